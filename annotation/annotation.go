@@ -0,0 +1,216 @@
+// Package annotation keeps the personal annotations of comics: tags, favorites, notes and ratings. The
+// annotations are stored apart from the comics downloaded from the web site, so syncing never changes them.
+package annotation
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// MaxRating is the highest rating of a comic. A rating of 0 means that the comic is not rated.
+const MaxRating = 5
+
+// Annotation holds the annotations of a single comic.
+type Annotation struct {
+	Tags     []string  `json:"tags,omitempty"`
+	Favorite bool      `json:"favorite,omitempty"`
+	Note     string    `json:"note,omitempty"`
+	Rating   int       `json:"rating,omitempty"`
+	Updated  time.Time `json:"updated"`
+}
+
+// HasTag returns true if the comic is tagged with tag.
+func (a Annotation) HasTag(tag string) bool {
+	tag = normalizeTag(tag)
+
+	for _, t := range a.Tags {
+		if t == tag {
+			return true
+		}
+	}
+
+	return false
+}
+
+// empty returns true if the annotation holds nothing, it is then removed from the store.
+func (a Annotation) empty() bool {
+	return len(a.Tags) == 0 && !a.Favorite && a.Note == "" && a.Rating == 0
+}
+
+// Store holds the annotations of the comics by the comic number. It is safe for concurrent use.
+type Store struct {
+	mu          sync.RWMutex
+	annotations map[int]Annotation
+}
+
+// NewStore creates a store with the annotations, usually read from the annotations file.
+func NewStore(annotations map[int]Annotation) *Store {
+	s := &Store{annotations: make(map[int]Annotation, len(annotations))}
+
+	for num, a := range annotations {
+		a.Tags = normalizeTags(a.Tags)
+		s.annotations[num] = a
+	}
+
+	return s
+}
+
+// All returns a copy of the annotations, for example to write them to the annotations file.
+func (s *Store) All() map[int]Annotation {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	result := make(map[int]Annotation, len(s.annotations))
+
+	for num, a := range s.annotations {
+		a.Tags = append([]string(nil), a.Tags...)
+		result[num] = a
+	}
+
+	return result
+}
+
+// Get returns the annotation of the comic, the zero Annotation if the comic is not annotated.
+func (s *Store) Get(comicNum int) Annotation {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	a := s.annotations[comicNum]
+	a.Tags = append([]string(nil), a.Tags...)
+
+	return a
+}
+
+// Tag adds the tags to the comic. Tags are lower case and cannot contain spaces.
+func (s *Store) Tag(comicNum int, tags ...string) error {
+	for _, tag := range tags {
+		if err := validateTag(tag); err != nil {
+			return err
+		}
+	}
+
+	s.update(comicNum, func(a *Annotation) {
+		a.Tags = normalizeTags(append(a.Tags, tags...))
+	})
+
+	return nil
+}
+
+// Untag removes the tags from the comic.
+func (s *Store) Untag(comicNum int, tags ...string) {
+	remove := make(map[string]bool)
+
+	for _, tag := range tags {
+		remove[normalizeTag(tag)] = true
+	}
+
+	s.update(comicNum, func(a *Annotation) {
+		kept := a.Tags[:0]
+
+		for _, tag := range a.Tags {
+			if !remove[tag] {
+				kept = append(kept, tag)
+			}
+		}
+
+		a.Tags = kept
+	})
+}
+
+// SetFavorite marks or unmarks the comic as a favorite.
+func (s *Store) SetFavorite(comicNum int, favorite bool) {
+	s.update(comicNum, func(a *Annotation) { a.Favorite = favorite })
+}
+
+// SetNote sets the note of the comic, an empty note removes it.
+func (s *Store) SetNote(comicNum int, note string) {
+	s.update(comicNum, func(a *Annotation) { a.Note = strings.TrimSpace(note) })
+}
+
+// SetRating rates the comic from 1 to MaxRating, 0 removes the rating.
+func (s *Store) SetRating(comicNum int, rating int) error {
+	if rating < 0 || rating > MaxRating {
+		return fmt.Errorf("rating must be between 0 and %d, got %d", MaxRating, rating)
+	}
+
+	s.update(comicNum, func(a *Annotation) { a.Rating = rating })
+
+	return nil
+}
+
+// TagCounts returns the number of comics tagged with each tag.
+func (s *Store) TagCounts() map[string]int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	result := make(map[string]int)
+
+	for _, a := range s.annotations {
+		for _, tag := range a.Tags {
+			result[tag]++
+		}
+	}
+
+	return result
+}
+
+// update changes the annotation of the comic under the lock and records the time of the change.
+func (s *Store) update(comicNum int, change func(a *Annotation)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.annotations == nil {
+		s.annotations = make(map[int]Annotation)
+	}
+
+	a := s.annotations[comicNum]
+	a.Tags = append([]string(nil), a.Tags...)
+	change(&a)
+	a.Updated = now()
+
+	if a.empty() {
+		delete(s.annotations, comicNum)
+		return
+	}
+
+	s.annotations[comicNum] = a
+}
+
+// now returns the current time, it is replaced in tests.
+var now = time.Now
+
+// validateTag returns an error for an empty tag or a tag with spaces or a colon, which is used by search filters.
+func validateTag(tag string) error {
+	tag = normalizeTag(tag)
+
+	if tag == "" || strings.ContainsAny(tag, " \t:") {
+		return fmt.Errorf("invalid tag %q", tag)
+	}
+
+	return nil
+}
+
+// normalizeTag returns the tag in lower case without the surrounding spaces.
+func normalizeTag(tag string) string {
+	return strings.ToLower(strings.TrimSpace(tag))
+}
+
+// normalizeTags returns the sorted unique normalized tags.
+func normalizeTags(tags []string) []string {
+	seen := make(map[string]bool)
+	var result []string
+
+	for _, tag := range tags {
+		if tag = normalizeTag(tag); tag != "" && !seen[tag] {
+			seen[tag] = true
+			result = append(result, tag)
+		}
+	}
+
+	sort.Strings(result)
+
+	return result
+}
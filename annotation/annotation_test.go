@@ -0,0 +1,95 @@
+package annotation
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestStore(t *testing.T) {
+	saved := now
+	defer func() { now = saved }()
+
+	now = func() time.Time { return time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC) }
+
+	s := NewStore(nil)
+
+	if err := s.Tag(1, "Security", "git", "security"); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := s.Tag(1, "two words"); err == nil {
+		t.Errorf("expected an error for a tag with a space")
+	}
+
+	s.SetFavorite(1, true)
+	s.SetNote(1, "  use in the onboarding slides ")
+
+	if err := s.SetRating(1, MaxRating+1); err == nil {
+		t.Errorf("expected an error for a rating out of range")
+	}
+
+	s.SetRating(1, 4)
+	s.Tag(2, "git")
+
+	want := Annotation{Tags: []string{"git", "security"}, Favorite: true, Note: "use in the onboarding slides", Rating: 4, Updated: now()}
+
+	if got := s.Get(1); !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %+v, got %+v", want, got)
+	}
+
+	if counts := s.TagCounts(); counts["git"] != 2 || counts["security"] != 1 {
+		t.Errorf("unexpected tag counts %v", counts)
+	}
+
+	s.Untag(2, "GIT")
+
+	if _, ok := s.All()[2]; ok {
+		t.Errorf("expected an annotation without content to be removed")
+	}
+}
+
+func TestParseFilter(t *testing.T) {
+	a := Annotation{Tags: []string{"security"}, Favorite: true, Note: "Slides", Rating: 4}
+
+	tests := []struct {
+		field, value string
+		want         bool
+	}{
+		{"tag", "security", true},
+		{"tag", "Security", true},
+		{"tag", "git", false},
+		{"favorite", "true", true},
+		{"favorite", "false", false},
+		{"rating", ">=4", true},
+		{"rating", ">4", false},
+		{"rating", "4", true},
+		{"rating", "<3", false},
+		{"rating", "3..5", true},
+		{"rating", "..3", false},
+		{"note", "slides", true},
+	}
+
+	for _, tt := range tests {
+		filter, ok, err := ParseFilter(tt.field, tt.value)
+
+		if err != nil || !ok {
+			t.Errorf("%s:%s: unexpected %v, %v", tt.field, tt.value, ok, err)
+			continue
+		}
+
+		if got := filter(a); got != tt.want {
+			t.Errorf("%s:%s: expected %v, got %v", tt.field, tt.value, tt.want, got)
+		}
+	}
+
+	if _, ok, _ := ParseFilter("title", "x"); ok {
+		t.Errorf("expected title not to be an annotation field")
+	}
+
+	for _, term := range [][2]string{{"favorite", "maybe"}, {"rating", "high"}, {"tag", ""}} {
+		if _, _, err := ParseFilter(term[0], term[1]); err == nil {
+			t.Errorf("%s:%s: expected an error", term[0], term[1])
+		}
+	}
+}
@@ -0,0 +1,51 @@
+package annotation
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"xkcd2/tools/util"
+)
+
+// Filter is a condition on the annotation of a comic.
+type Filter func(a Annotation) bool
+
+// Fields are the annotation fields that can be searched.
+var Fields = []string{"tag", "favorite", "rating", "note"}
+
+// ParseFilter parses the search term field:value for one of Fields, for example tag:security, favorite:true,
+// rating:>=4 or note:slides. The rating accepts a range such as 2..4 and the comparisons =, <, <=, > and >=,
+// see util.ParseRange. ok is false if the field is not an annotation field.
+func ParseFilter(field, value string) (filter Filter, ok bool, err error) {
+	switch strings.ToLower(field) {
+	case "tag":
+		if err := validateTag(value); err != nil {
+			return nil, true, err
+		}
+
+		return func(a Annotation) bool { return a.HasTag(value) }, true, nil
+	case "favorite":
+		favorite, err := strconv.ParseBool(value)
+
+		if err != nil {
+			return nil, true, fmt.Errorf("invalid favorite %q, expected true or false", value)
+		}
+
+		return func(a Annotation) bool { return a.Favorite == favorite }, true, nil
+	case "rating":
+		low, high, err := util.ParseRange(value)
+
+		if err != nil {
+			return nil, true, err
+		}
+
+		return func(a Annotation) bool { return a.Rating >= low && a.Rating <= high }, true, nil
+	case "note":
+		text := strings.ToLower(value)
+
+		return func(a Annotation) bool { return text != "" && strings.Contains(strings.ToLower(a.Note), text) }, true, nil
+	}
+
+	return nil, false, nil
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"fmt"
+
+	"xkcd2/comic"
+)
+
+func init() {
+	commands["analyze"] = &command{
+		usage: "analyze [-force]",
+		help:  "records the dimensions, format, frames and colorfulness of the stored images",
+		run:   runAnalyze,
+	}
+}
+
+// runAnalyze decodes the stored images that were not analyzed yet, or all of them with -force, records
+// their metadata and writes the index file. The images downloaded by sync are analyzed when they are stored.
+func runAnalyze(args []string) error {
+	fs := newFlagSet("analyze")
+	force := fs.Bool("force", false, "analyze the images again")
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	var pending []int
+
+	comics.Query().Filter(func(xkcd *comic.XKCD) bool {
+		return xkcd.Image != "" && (*force || !xkcd.Analyzed())
+	}).Each(func(xkcd comic.XKCD) bool {
+		pending = append(pending, xkcd.Number)
+		return true
+	})
+
+	analyzed := 0
+
+	for _, num := range pending {
+		comics.Update(num, func(xkcd *comic.XKCD) {
+			if err := xkcd.Analyze(); err != nil {
+				fmt.Printf("%d: %v\n", num, err)
+				return
+			}
+
+			analyzed++
+		})
+	}
+
+	fmt.Printf("Analyzed images: %d, failed: %d\n", analyzed, len(pending)-analyzed)
+
+	if analyzed > 0 {
+		writeComics()
+	}
+
+	return nil
+}
@@ -0,0 +1,218 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"xkcd2/annotation"
+	"xkcd2/persistence"
+)
+
+func init() {
+	commands["tag"] = &command{
+		usage: "tag <n> <tag> [tag ...]",
+		help:  "adds tags to comic n",
+		run:   runTag,
+	}
+	commands["untag"] = &command{
+		usage: "untag <n> <tag> [tag ...]",
+		help:  "removes tags from comic n",
+		run:   runUntag,
+	}
+	commands["tags"] = &command{
+		usage: "tags [n]",
+		help:  "lists all the tags with the number of comics, or the annotations of comic n",
+		run:   runTags,
+	}
+	commands["fav"] = &command{
+		usage: "fav [-off] <n>",
+		help:  "marks comic n as a favorite",
+		run:   runFavorite,
+	}
+	commands["note"] = &command{
+		usage: "note <n> [text ...]",
+		help:  "sets the note of comic n, without text the note is removed",
+		run:   runNote,
+	}
+	commands["rate"] = &command{
+		usage: fmt.Sprintf("rate <n> <0-%d>", annotation.MaxRating),
+		help:  "rates comic n, 0 removes the rating",
+		run:   runRate,
+	}
+}
+
+// loadAnnotations reads the annotations file.
+func loadAnnotations() (*annotation.Store, error) {
+	annotations, err := persistence.ReadAnnotationsFile()
+
+	if err != nil {
+		return nil, err
+	}
+
+	return annotation.NewStore(annotations), nil
+}
+
+// annotate parses the comic number in the first argument, calls change with the annotations and
+// writes them back. The comic must be in the index.
+func annotate(name string, args []string, change func(store *annotation.Store, comicNum int, args []string) error) error {
+	if len(args) == 0 {
+		return fmt.Errorf("%s: expected a comic number", name)
+	}
+
+	comicNum, err := strconv.Atoi(args[0])
+
+	if err != nil {
+		return fmt.Errorf("%s: invalid comic number %q", name, args[0])
+	}
+
+	if !comics.Contains(comicNum) {
+		return fmt.Errorf("%s: comic %d not found", name, comicNum)
+	}
+
+	store, err := loadAnnotations()
+
+	if err != nil {
+		return err
+	}
+
+	if err := change(store, comicNum, args[1:]); err != nil {
+		return fmt.Errorf("%s: %v", name, err)
+	}
+
+	if err := persistence.WriteAnnotationsFile(store.All()); err != nil {
+		return err
+	}
+
+	printAnnotation(comicNum, store.Get(comicNum))
+
+	return nil
+}
+
+// runTag adds the tags to a comic.
+func runTag(args []string) error {
+	return annotate("tag", args, func(store *annotation.Store, comicNum int, tags []string) error {
+		if len(tags) == 0 {
+			return fmt.Errorf("expected at least one tag")
+		}
+
+		return store.Tag(comicNum, tags...)
+	})
+}
+
+// runUntag removes the tags from a comic.
+func runUntag(args []string) error {
+	return annotate("untag", args, func(store *annotation.Store, comicNum int, tags []string) error {
+		store.Untag(comicNum, tags...)
+		return nil
+	})
+}
+
+// runFavorite marks a comic as a favorite, or unmarks it with -off.
+func runFavorite(args []string) error {
+	fs := newFlagSet("fav")
+	off := fs.Bool("off", false, "unmark the comic")
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	return annotate("fav", fs.Args(), func(store *annotation.Store, comicNum int, _ []string) error {
+		store.SetFavorite(comicNum, !*off)
+		return nil
+	})
+}
+
+// runNote sets the note of a comic to the rest of the arguments.
+func runNote(args []string) error {
+	return annotate("note", args, func(store *annotation.Store, comicNum int, words []string) error {
+		store.SetNote(comicNum, strings.Join(words, " "))
+		return nil
+	})
+}
+
+// runRate sets the rating of a comic.
+func runRate(args []string) error {
+	return annotate("rate", args, func(store *annotation.Store, comicNum int, rest []string) error {
+		if len(rest) != 1 {
+			return fmt.Errorf("expected one rating")
+		}
+
+		rating, err := strconv.Atoi(rest[0])
+
+		if err != nil {
+			return fmt.Errorf("invalid rating %q", rest[0])
+		}
+
+		return store.SetRating(comicNum, rating)
+	})
+}
+
+// runTags lists the tags in use, or the annotations of a single comic.
+func runTags(args []string) error {
+	store, err := loadAnnotations()
+
+	if err != nil {
+		return err
+	}
+
+	if len(args) > 0 {
+		comicNum, err := strconv.Atoi(args[0])
+
+		if err != nil {
+			return fmt.Errorf("tags: invalid comic number %q", args[0])
+		}
+
+		printAnnotation(comicNum, store.Get(comicNum))
+
+		return nil
+	}
+
+	counts := store.TagCounts()
+	tags := make([]string, 0, len(counts))
+
+	for tag := range counts {
+		tags = append(tags, tag)
+	}
+
+	sort.Strings(tags)
+
+	for _, tag := range tags {
+		fmt.Printf("%s\t%d\n", tag, counts[tag])
+	}
+
+	return nil
+}
+
+// printAnnotation writes the annotation of a comic on a single line.
+func printAnnotation(comicNum int, a annotation.Annotation) {
+	fmt.Printf("%d: %s\n", comicNum, formatAnnotation(a))
+}
+
+// formatAnnotation returns a short description of the annotation.
+func formatAnnotation(a annotation.Annotation) string {
+	var parts []string
+
+	if a.Favorite {
+		parts = append(parts, "favorite")
+	}
+
+	if a.Rating > 0 {
+		parts = append(parts, fmt.Sprintf("rating %d/%d", a.Rating, annotation.MaxRating))
+	}
+
+	if len(a.Tags) > 0 {
+		parts = append(parts, "tags "+strings.Join(a.Tags, ", "))
+	}
+
+	if a.Note != "" {
+		parts = append(parts, fmt.Sprintf("note %q", a.Note))
+	}
+
+	if len(parts) == 0 {
+		return "no annotations"
+	}
+
+	return strings.Join(parts, "; ")
+}
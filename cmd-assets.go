@@ -0,0 +1,79 @@
+package main
+
+import (
+	"fmt"
+
+	"xkcd2/comic"
+	"xkcd2/persistence"
+)
+
+func init() {
+	commands["assets"] = &command{
+		usage: "assets [-from n] [-to n]",
+		help:  "downloads the high resolution and large variants of the stored comics into the blob store",
+		run:   runAssets,
+	}
+}
+
+// runAssets downloads the variants of the comics in the range that were not downloaded yet and
+// writes the index file with the new assets.
+func runAssets(args []string) error {
+	fs := newFlagSet("assets")
+	from := fs.Int("from", 0, "first comic number")
+	to := fs.Int("to", 0, "last comic number")
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	items := comics.Query().Range(*from, *to).All()
+	resultChan := make(chan downloadResult)
+
+	// counting semaphore that limits the number of concurrent downloads, the same as in fetchComics
+	semaphore := make(chan struct{}, 20)
+
+	for i := range items {
+		go func(xkcd *comic.XKCD) {
+			semaphore <- struct{}{}
+			defer func() { <-semaphore }()
+
+			before := len(xkcd.Assets)
+			err := xkcd.DownloadAssets(persistence.WriteBlob)
+
+			if err != nil || len(xkcd.Assets) == before {
+				resultChan <- downloadResult{xkcd.Number, nil, err}
+				return
+			}
+
+			resultChan <- downloadResult{xkcd.Number, xkcd, nil}
+		}(&items[i])
+	}
+
+	updated, failed := 0, 0
+
+	for range items {
+		result := <-resultChan
+
+		switch {
+		case result.err != nil:
+			failed++
+			fmt.Printf("%d: %v\n", result.comicNum, result.err)
+		case result.xkcd != nil:
+			updated++
+
+			comics.Update(result.comicNum, func(xkcd *comic.XKCD) {
+				for _, asset := range result.xkcd.Assets {
+					xkcd.SetAsset(asset)
+				}
+			})
+		}
+	}
+
+	fmt.Printf("\nComics: %d, with new assets: %d, failed: %d\n", len(items), updated, failed)
+
+	if updated > 0 {
+		writeComics()
+	}
+
+	return nil
+}
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"xkcd2/comic"
+)
+
+func init() {
+	commands["dates"] = &command{
+		usage: "dates [filters]",
+		help:  "lists comics by publication date; filters can be combined",
+		run:   runDates,
+	}
+}
+
+// runDates lists the comics that match all the given date filters.
+func runDates(args []string) error {
+	fs := newFlagSet("dates")
+	year := fs.Int("year", 0, "published in year")
+	month := fs.Int("month", 0, "published in month (1-12)")
+	weekday := fs.String("weekday", "", "published on weekday, e.g. monday")
+	from := fs.String("from", "", "published on or after date (yyyy-mm-dd)")
+	to := fs.String("to", "", "published on or before date (yyyy-mm-dd)")
+	today := fs.Bool("today", false, "published on this day in any year")
+	invalid := fs.Bool("invalid", false, "list comics with a malformed date instead")
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if *invalid {
+		invalidDates := comics.InvalidDates()
+		numbers := make([]int, 0, len(invalidDates))
+
+		for num := range invalidDates {
+			numbers = append(numbers, num)
+		}
+
+		sort.Ints(numbers)
+
+		for _, num := range numbers {
+			fmt.Printf("%d: %v\n", num, invalidDates[num])
+		}
+
+		return nil
+	}
+
+	var filters []func(time.Time) bool
+
+	if *year != 0 {
+		filters = append(filters, func(date time.Time) bool { return date.Year() == *year })
+	}
+
+	if *month != 0 {
+		filters = append(filters, func(date time.Time) bool { return int(date.Month()) == *month })
+	}
+
+	if *weekday != "" {
+		wd, err := parseWeekday(*weekday)
+
+		if err != nil {
+			return err
+		}
+
+		filters = append(filters, func(date time.Time) bool { return date.Weekday() == wd })
+	}
+
+	for _, bound := range []struct {
+		value string
+		after bool
+	}{{*from, true}, {*to, false}} {
+		if bound.value == "" {
+			continue
+		}
+
+		limit, err := time.Parse(comic.DateLayout, bound.value)
+
+		if err != nil {
+			return fmt.Errorf("invalid date %q: %v", bound.value, err)
+		}
+
+		if bound.after {
+			filters = append(filters, func(date time.Time) bool { return !date.Before(limit) })
+		} else {
+			filters = append(filters, func(date time.Time) bool { return !date.After(limit) })
+		}
+	}
+
+	if *today {
+		now := time.Now()
+		filters = append(filters, func(date time.Time) bool {
+			return date.Month() == now.Month() && date.Day() == now.Day()
+		})
+	}
+
+	query := comics.Query()
+
+	for _, match := range filters {
+		query.Filter(comic.DateFilter(match))
+	}
+
+	result := query.All()
+
+	for _, item := range result {
+		date, _ := item.Date()
+		fmt.Printf("%d,%s,%s\n", item.Number, date.Format(comic.DateLayout), item.Title)
+	}
+
+	fmt.Printf("\nMatching comics: %d\n", len(result))
+
+	return nil
+}
+
+// parseWeekday converts a weekday name, or its first three letters, into time.Weekday.
+func parseWeekday(name string) (time.Weekday, error) {
+	name = strings.ToLower(name)
+
+	for wd := time.Sunday; wd <= time.Saturday; wd++ {
+		full := strings.ToLower(wd.String())
+
+		if name == full || name == full[:3] {
+			return wd, nil
+		}
+	}
+
+	return 0, fmt.Errorf("invalid weekday %q", name)
+}
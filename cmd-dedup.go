@@ -0,0 +1,49 @@
+package main
+
+import (
+	"fmt"
+
+	"xkcd2/comic"
+	"xkcd2/persistence"
+)
+
+func init() {
+	commands["dedup"] = &command{
+		usage: "dedup [-fix]",
+		help:  "reports the comics stored more than once in the index file",
+		run:   runDedup,
+	}
+}
+
+// runDedup reads the index file as it is stored and reports the duplicated comic numbers. The loaded
+// collection is already merged, so fixing the index only needs writing it back.
+func runDedup(args []string) error {
+	fs := newFlagSet("dedup")
+	fix := fs.Bool("fix", false, "rewrite the index file with the merged comics")
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	items, err := persistence.ReadIndexFile()
+
+	if err != nil {
+		return err
+	}
+
+	unique, duplicates := comic.Dedup(items)
+
+	for _, dup := range duplicates {
+		fmt.Printf("%d: stored %d times\n", dup.Number, dup.Count)
+	}
+
+	fmt.Printf("\nStored records: %d, unique comics: %d, duplicated comics: %d\n",
+		len(items), len(unique), len(duplicates))
+
+	if *fix && len(duplicates) > 0 {
+		writeComics()
+		fmt.Println("Index file rewritten")
+	}
+
+	return nil
+}
@@ -0,0 +1,172 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"xkcd2/comic"
+	"xkcd2/config"
+	"xkcd2/persistence"
+	"xkcd2/tools/util"
+)
+
+func init() {
+	commands["diff"] = &command{
+		usage: "diff [-json] [-live] [left.idx] [right.idx]",
+		help:  "compares two index files, or an index file with the live site",
+		run:   runDiff,
+	}
+}
+
+// runDiff compares two archives. With two files, it compares them. With one file, it compares the local
+// index with the file. With -live, it compares the given file, or the local index, with the xkcd web site.
+func runDiff(args []string) error {
+	fs := newFlagSet("diff")
+	asJSON := fs.Bool("json", false, "output the difference as JSON")
+	live := fs.Bool("live", false, "compare with the comics on the xkcd web site")
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	files := fs.Args()
+
+	if (*live && len(files) > 1) || (!*live && (len(files) < 1 || len(files) > 2)) {
+		fs.Usage()
+		return fmt.Errorf("unexpected number of index files")
+	}
+
+	if !*live && len(files) == 1 {
+		files = []string{util.GetIndexFile(), files[0]}
+	}
+
+	if *live && len(files) == 0 {
+		files = []string{util.GetIndexFile()}
+	}
+
+	left, err := persistence.ReadIndexFileFrom(files[0])
+
+	if err != nil {
+		return err
+	}
+
+	var right []comic.XKCD
+	var failed []int
+	rightName := "live"
+
+	if *live {
+		if right, failed, err = downloadAll(); err != nil {
+			return err
+		}
+
+		left = withoutComics(left, failed)
+	} else {
+		rightName = files[1]
+
+		if right, err = persistence.ReadIndexFileFrom(files[1]); err != nil {
+			return err
+		}
+	}
+
+	result := comic.CompareArchives(left, right)
+	result.Unavailable = failed
+
+	if *asJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+
+		return encoder.Encode(result)
+	}
+
+	printDiff(files[0], rightName, result)
+
+	return nil
+}
+
+// downloadAll downloads every comic from the xkcd web site. The numbers of the comics that fail to download
+// are returned, so they can be left out of the comparison instead of being mistaken for comics missing
+// from the site.
+func downloadAll() ([]comic.XKCD, []int, error) {
+	last, err := latestComicNum()
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	numbers := make([]int, 0, last)
+
+	for num := 1; num <= last; num++ {
+		if !config.IsAbsent(num) {
+			numbers = append(numbers, num)
+		}
+	}
+
+	var result []comic.XKCD
+	var failed []int
+
+	for _, res := range downloadComics(numbers) {
+		if res.err != nil {
+			fmt.Fprintf(os.Stderr, "%d: failed: %v\n", res.comicNum, res.err)
+			failed = append(failed, res.comicNum)
+			continue
+		}
+
+		result = append(result, *res.xkcd)
+	}
+
+	return result, failed, nil
+}
+
+// withoutComics returns the items whose number is not in numbers.
+func withoutComics(items []comic.XKCD, numbers []int) []comic.XKCD {
+	if len(numbers) == 0 {
+		return items
+	}
+
+	excluded := make(map[int]bool, len(numbers))
+
+	for _, num := range numbers {
+		excluded[num] = true
+	}
+
+	result := make([]comic.XKCD, 0, len(items))
+
+	for _, xkcd := range items {
+		if !excluded[xkcd.Number] {
+			result = append(result, xkcd)
+		}
+	}
+
+	return result
+}
+
+// printDiff writes the difference in a human readable form.
+func printDiff(leftName, rightName string, d comic.ArchiveDiff) {
+	if d.Empty() {
+		fmt.Println("The archives are the same")
+		return
+	}
+
+	fmt.Printf("--- %s\n+++ %s\n", leftName, rightName)
+
+	if len(d.OnlyLeft) > 0 {
+		fmt.Printf("\nOnly in %s (%d): %v\n", leftName, len(d.OnlyLeft), d.OnlyLeft)
+	}
+
+	if len(d.OnlyRight) > 0 {
+		fmt.Printf("\nOnly in %s (%d): %v\n", rightName, len(d.OnlyRight), d.OnlyRight)
+	}
+
+	if len(d.Unavailable) > 0 {
+		fmt.Printf("\nCould not download from %s, not compared (%d): %v\n", rightName, len(d.Unavailable), d.Unavailable)
+	}
+
+	for _, changed := range d.Changed {
+		fmt.Printf("\n%d:\n", changed.Number)
+
+		for _, change := range changed.Changes {
+			fmt.Printf("  %s\n  - %q\n  + %q\n", change.Field, change.Old, change.New)
+		}
+	}
+}
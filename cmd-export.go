@@ -0,0 +1,67 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"xkcd2/comic"
+	"xkcd2/export"
+)
+
+func init() {
+	commands["export"] = &command{
+		usage: "export [-format json|jsonl|csv] [-raw] [-asset role] [-from n] [-to n] [-q query] [-o file]",
+		help:  "exports the comics for other programs",
+		run:   runExport,
+	}
+}
+
+// runExport writes the comics in the range to the standard output or to a file.
+func runExport(args []string) error {
+	fs := newFlagSet("export")
+	format := fs.String("format", export.JSON, "output format: json, jsonl or csv")
+	raw := fs.Bool("raw", false, "export the JSON documents as received from the web site")
+	asset := fs.String("asset", "", "role of the asset exported as the image URL, for example 2x")
+	from := fs.Int("from", 0, "first comic number")
+	to := fs.Int("to", 0, "last comic number")
+	q := fs.String("q", "", "search query selecting the comics, see the search command")
+	out := fs.String("o", "", "output file, the standard output by default")
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	query := comics.Query().Range(*from, *to)
+
+	if *q != "" {
+		store, err := loadAnnotations()
+
+		if err != nil {
+			return err
+		}
+
+		filter, err := comic.ParseSearch(*q, annotationField(store))
+
+		if err != nil {
+			return err
+		}
+
+		query = query.Filter(filter)
+	}
+
+	var w io.Writer = os.Stdout
+
+	if *out != "" {
+		file, err := os.Create(*out)
+
+		if err != nil {
+			return fmt.Errorf("export: %v", err)
+		}
+
+		defer file.Close()
+		w = file
+	}
+
+	return export.Write(w, query.All(), export.Options{Format: *format, Raw: *raw, Asset: *asset})
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"fmt"
+
+	"xkcd2/config"
+	"xkcd2/feed"
+	"xkcd2/tools/util"
+)
+
+func init() {
+	commands["feed"] = &command{
+		usage: "feed [-format atom|rss] [-n count] [-o file] [-self url]",
+		help:  "writes a feed of the latest comics",
+		run:   runFeed,
+	}
+}
+
+// runFeed writes a single feed in the requested format.
+func runFeed(args []string) error {
+	fs := newFlagSet("feed")
+	format := fs.String("format", feed.Atom, "feed format, atom or rss")
+	count := fs.Int("n", config.FeedSize, "number of latest comics in the feed")
+	out := fs.String("o", "", "output file (default atom.xml or rss.xml in the .xkcd folder)")
+	self := fs.String("self", "", "address the feed is published at, used as the Atom self link")
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if *out == "" {
+		*out = feedFile(*format)
+	}
+
+	if err := feed.Write(*out, *format, comics.GetAll(), *count, *self); err != nil {
+		return err
+	}
+
+	fmt.Printf("Feed written to %s\n", *out)
+
+	return nil
+}
+
+// writeFeeds writes both the Atom and the RSS feed of the latest comics into the .xkcd folder.
+func writeFeeds() error {
+	for _, format := range []string{feed.Atom, feed.RSS} {
+		if err := feed.Write(feedFile(format), format, comics.GetAll(), config.FeedSize, ""); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// feedFile returns the default location of the feed in format.
+func feedFile(format string) string {
+	if format == feed.RSS {
+		return util.GetRSSFile()
+	}
+
+	return util.GetAtomFile()
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"fmt"
+)
+
+func init() {
+	commands["gaps"] = &command{
+		usage: "gaps [-live]",
+		help:  "lists the comic numbers missing from the offline index",
+		run:   runGaps,
+	}
+
+	commands["repair"] = &command{
+		usage: "repair [-live]",
+		help:  "downloads only the comics missing from the offline index",
+		run:   runRepair,
+	}
+}
+
+// runGaps prints the missing comic numbers.
+func runGaps(args []string) error {
+	fs := newFlagSet("gaps")
+	live := fs.Bool("live", false, "look for gaps up to the latest comic on the web site")
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	missing, err := findGaps(*live)
+
+	if err != nil {
+		return err
+	}
+
+	for _, num := range missing {
+		fmt.Println(num)
+	}
+
+	fmt.Printf("\nMissing comics: %d\n", len(missing))
+
+	return nil
+}
+
+// runRepair downloads the missing comics, reports the outcome for each of them and writes
+// the index file if at least one comic was added.
+func runRepair(args []string) error {
+	fs := newFlagSet("repair")
+	live := fs.Bool("live", false, "repair gaps up to the latest comic on the web site")
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	missing, err := findGaps(*live)
+
+	if err != nil {
+		return err
+	}
+
+	results := downloadComics(missing)
+	failed := 0
+
+	for _, result := range results {
+		if result.err == nil {
+			comics.Add(result.xkcd)
+		}
+	}
+
+	for _, result := range results {
+		if result.err != nil {
+			failed++
+			fmt.Printf("%d: failed: %v\n", result.comicNum, result.err)
+		} else {
+			fmt.Printf("%d: ok\n", result.comicNum)
+		}
+	}
+
+	fmt.Printf("\nRepaired: %d, failed: %d\n", len(results)-failed, failed)
+
+	if failed < len(results) {
+		writeComics()
+	}
+
+	return nil
+}
+
+// findGaps returns the missing comic numbers up to the highest stored number or, if live is true,
+// up to the latest comic on the xkcd web site.
+func findGaps(live bool) ([]int, error) {
+	last := 0
+
+	if live {
+		var err error
+
+		if last, err = latestComicNum(); err != nil {
+			return nil, err
+		}
+	}
+
+	return comics.Missing(last), nil
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+)
+
+func init() {
+	commands["history"] = &command{
+		usage: "history <n>",
+		help:  "shows how the title, alt text, transcript and other fields of a comic changed over time",
+		run:   runHistory,
+	}
+}
+
+// runHistory prints the revisions of a single comic, the oldest first.
+func runHistory(args []string) error {
+	fs := newFlagSet("history")
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return fmt.Errorf("expected a comic number")
+	}
+
+	comicNum, err := strconv.Atoi(fs.Arg(0))
+
+	if err != nil {
+		return fmt.Errorf("invalid comic number %q", fs.Arg(0))
+	}
+
+	if !comics.Contains(comicNum) {
+		return fmt.Errorf("comic %d is not in the index", comicNum)
+	}
+
+	revisions := comics.History(comicNum)
+
+	if len(revisions) == 0 {
+		fmt.Printf("Comic %d has not changed since it was downloaded\n", comicNum)
+		return nil
+	}
+
+	for _, revision := range revisions {
+		fmt.Printf("%s\n", revision.Observed.Format("2006-01-02 15:04:05"))
+
+		for _, change := range revision.Changes {
+			fmt.Printf("  %s: %q -> %q\n", change.Field, change.Old, change.New)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"fmt"
+
+	"xkcd2/comic"
+	"xkcd2/tools/imaging"
+)
+
+func init() {
+	commands["ls"] = &command{
+		usage: "ls [-from n] [-to n] [-order number|date|title] [-desc] [-size n] [-cursor c] [-animated] [-color] [-bw] [-oversized] [-format f] [-speaker name]",
+		help:  "lists comics page by page",
+		run:   runList,
+	}
+}
+
+// runList prints a page of comics and the cursor of the next page.
+func runList(args []string) error {
+	fs := newFlagSet("ls")
+	from := fs.Int("from", 0, "lowest comic number")
+	to := fs.Int("to", 0, "highest comic number")
+	orderName := fs.String("order", "number", "order by number, date or title")
+	desc := fs.Bool("desc", false, "descending order")
+	size := fs.Int("size", 50, "page size, 0 lists all the comics")
+	cursor := fs.String("cursor", "", "cursor of the page returned by the previous call")
+	animated := fs.Bool("animated", false, "only comics with an animated image")
+	color := fs.Bool("color", false, "only comics with a color image")
+	bw := fs.Bool("bw", false, "only comics with a black and white image")
+	oversized := fs.Bool("oversized", false, "only comics with an oversized image")
+	format := fs.String("format", "", "only comics with an image in format png, jpeg or gif")
+	speaker := fs.String("speaker", "", "only comics where the speaker speaks in the transcript")
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	order, err := comic.ParseOrder(*orderName)
+
+	if err != nil {
+		return err
+	}
+
+	query := comics.Query().Range(*from, *to).OrderBy(order, *desc)
+
+	// the image filters need the metadata recorded by the analyze command
+	for _, filter := range []struct {
+		enabled bool
+		match   func(metadata imaging.Metadata) bool
+	}{
+		{*animated, imaging.Metadata.Animated},
+		{*color, imaging.Metadata.Color},
+		{*bw, func(metadata imaging.Metadata) bool { return !metadata.Color() }},
+		{*oversized, comic.Oversized},
+		{*format != "", func(metadata imaging.Metadata) bool { return metadata.Format == *format }},
+	} {
+		if filter.enabled {
+			query = query.Filter(comic.MetadataFilter(filter.match))
+		}
+	}
+
+	if *speaker != "" {
+		query = query.Filter(comic.SpeakerFilter(*speaker))
+	}
+
+	page, next, err := query.Page(*cursor, *size)
+
+	if err != nil {
+		return err
+	}
+
+	for _, item := range page {
+		date := ""
+
+		if d, err := item.Date(); err == nil {
+			date = d.Format(comic.DateLayout)
+		}
+
+		fmt.Printf("%d,%s,%s\n", item.Number, date, item.Title)
+	}
+
+	if next != "" {
+		fmt.Printf("\nNext page: -cursor %s\n", next)
+	}
+
+	return nil
+}
@@ -0,0 +1,273 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+
+	"xkcd2/comic"
+	"xkcd2/playlist"
+	"xkcd2/tools/util"
+)
+
+func init() {
+	commands["playlist"] = &command{
+		usage: "playlist list | create [-d text] <name> | add [-at pos] [-force] <name> <n>... | rm <name> <n>... | show <name> | delete <name> | export [-format json|html|md] [-o file] <name>",
+		help:  "manages named lists of comics",
+		run:   runPlaylist,
+	}
+}
+
+// playlistStore returns the store of playlists in the XKCD folder.
+func playlistStore() *playlist.Store {
+	return playlist.NewStore(util.GetListsFolder())
+}
+
+// playlistCommands are the sub-commands of the playlist command.
+var playlistCommands = map[string]func(store *playlist.Store, args []string) error{
+	"list":   playlistList,
+	"create": playlistCreate,
+	"add":    playlistAdd,
+	"rm":     playlistRemove,
+	"show":   playlistShow,
+	"delete": playlistDelete,
+	"export": playlistExport,
+}
+
+// runPlaylist dispatches to the playlist sub-command.
+func runPlaylist(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("playlist: expected a sub-command, see %s", commands["playlist"].usage)
+	}
+
+	run, ok := playlistCommands[args[0]]
+
+	if !ok {
+		return fmt.Errorf("playlist: unknown sub-command %q", args[0])
+	}
+
+	return run(playlistStore(), args[1:])
+}
+
+func playlistList(store *playlist.Store, args []string) error {
+	names, err := store.List()
+
+	if err != nil {
+		return err
+	}
+
+	for _, name := range names {
+		p, err := store.Read(name)
+
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("%s\t%d comics\t%s\n", p.Name, len(p.Comics), p.Description)
+	}
+
+	return nil
+}
+
+func playlistCreate(store *playlist.Store, args []string) error {
+	fs := newFlagSet("playlist")
+	description := fs.String("d", "", "description of the playlist")
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if fs.NArg() != 1 {
+		return fmt.Errorf("playlist create: expected one name")
+	}
+
+	if _, err := store.Read(fs.Arg(0)); err != playlist.ErrNotFound {
+		if err == nil {
+			err = fmt.Errorf("playlist create: %q already exists", fs.Arg(0))
+		}
+
+		return err
+	}
+
+	p, err := playlist.New(fs.Arg(0), *description)
+
+	if err != nil {
+		return err
+	}
+
+	if err := store.Write(p); err != nil {
+		return err
+	}
+
+	fmt.Printf("Playlist %q created\n", p.Name)
+
+	return nil
+}
+
+func playlistAdd(store *playlist.Store, args []string) error {
+	fs := newFlagSet("playlist")
+	at := fs.Int("at", 0, "insert the comics before this position, counted from 1, instead of appending them")
+	force := fs.Bool("force", false, "add comics that are not in the index")
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	p, numbers, err := readPlaylistArgs(store, "playlist add", fs.Args())
+
+	if err != nil {
+		return err
+	}
+
+	for _, num := range numbers {
+		if !*force && !comics.Contains(num) {
+			return fmt.Errorf("playlist add: comic %d is not in the index, use -force to add it anyway", num)
+		}
+	}
+
+	added := p.Add(*at, numbers...)
+
+	if err := store.Write(p); err != nil {
+		return err
+	}
+
+	fmt.Printf("Added %d comics to %q, %d comics in total\n", added, p.Name, len(p.Comics))
+
+	return nil
+}
+
+func playlistRemove(store *playlist.Store, args []string) error {
+	p, numbers, err := readPlaylistArgs(store, "playlist rm", args)
+
+	if err != nil {
+		return err
+	}
+
+	removed := p.Remove(numbers...)
+
+	if err := store.Write(p); err != nil {
+		return err
+	}
+
+	fmt.Printf("Removed %d comics from %q, %d comics in total\n", removed, p.Name, len(p.Comics))
+
+	return nil
+}
+
+func playlistShow(store *playlist.Store, args []string) error {
+	p, _, err := readPlaylistArgs(store, "playlist show", args)
+
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%s (%d comics)\n", p.Name, len(p.Comics))
+
+	if p.Description != "" {
+		fmt.Println(p.Description)
+	}
+
+	fmt.Println()
+
+	for i, entry := range playlist.Resolve(p, getComic) {
+		if entry.Comic != nil {
+			fmt.Printf("%3d. %d: %s\n", i+1, entry.Number, entry.Comic.Title)
+		} else {
+			fmt.Printf("%3d. %d\n", i+1, entry.Number)
+		}
+	}
+
+	if problems := p.Validate(comics.Contains); len(problems) > 0 {
+		fmt.Println()
+
+		for _, problem := range problems {
+			fmt.Printf("Warning: comic %d: %s\n", problem.Number, problem.Reason)
+		}
+	}
+
+	return nil
+}
+
+func playlistDelete(store *playlist.Store, args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("playlist delete: expected one name")
+	}
+
+	if err := store.Delete(args[0]); err != nil {
+		return fmt.Errorf("playlist delete: %v", err)
+	}
+
+	fmt.Printf("Playlist %q deleted\n", args[0])
+
+	return nil
+}
+
+func playlistExport(store *playlist.Store, args []string) error {
+	fs := newFlagSet("playlist")
+	format := fs.String("format", playlist.JSON, "output format: json, html or md")
+	out := fs.String("o", "", "output file, the standard output by default")
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	p, _, err := readPlaylistArgs(store, "playlist export", fs.Args())
+
+	if err != nil {
+		return err
+	}
+
+	for _, problem := range p.Validate(comics.Contains) {
+		fmt.Fprintf(os.Stderr, "Warning: comic %d: %s\n", problem.Number, problem.Reason)
+	}
+
+	var w io.Writer = os.Stdout
+
+	if *out != "" {
+		file, err := os.Create(*out)
+
+		if err != nil {
+			return fmt.Errorf("playlist export: %v", err)
+		}
+
+		defer file.Close()
+		w = file
+	}
+
+	return playlist.Write(w, *format, p, playlist.Resolve(p, getComic), playlist.Links{})
+}
+
+// readPlaylistArgs reads the playlist named by the first argument and parses the comic numbers that follow.
+func readPlaylistArgs(store *playlist.Store, name string, args []string) (*playlist.Playlist, []int, error) {
+	if len(args) == 0 {
+		return nil, nil, fmt.Errorf("%s: expected a playlist name", name)
+	}
+
+	p, err := store.Read(args[0])
+
+	if err != nil {
+		return nil, nil, fmt.Errorf("%s: %q: %v", name, args[0], err)
+	}
+
+	var numbers []int
+
+	for _, arg := range args[1:] {
+		num, err := strconv.Atoi(arg)
+
+		if err != nil {
+			return nil, nil, fmt.Errorf("%s: invalid comic number %q", name, arg)
+		}
+
+		numbers = append(numbers, num)
+	}
+
+	return p, numbers, nil
+}
+
+// getComic returns a copy of the stored comic, or nil if it is not in the index.
+func getComic(comicNum int) *comic.XKCD {
+	_, xkcd := comics.Get(comicNum)
+
+	return xkcd
+}
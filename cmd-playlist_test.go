@@ -0,0 +1,29 @@
+package main
+
+import (
+	"os"
+	"os/exec"
+	"strings"
+	"testing"
+)
+
+// TestPlaylistFlags runs the playlist sub-commands with -h and an unknown flag in a child process, because
+// the flag sets exit on errors. Both must print the usage instead of panicking.
+func TestPlaylistFlags(t *testing.T) {
+	if args := os.Getenv("XKCD_PLAYLIST_ARGS"); args != "" {
+		runPlaylist(strings.Fields(args))
+		return
+	}
+
+	for _, sub := range []string{"create", "add", "export"} {
+		for _, flag := range []string{"-h", "-zzz"} {
+			cmd := exec.Command(os.Args[0], "-test.run=^TestPlaylistFlags$")
+			cmd.Env = append(os.Environ(), "XKCD_PLAYLIST_ARGS="+sub+" "+flag+" foo")
+			out, _ := cmd.CombinedOutput()
+
+			if strings.Contains(string(out), "panic") || !strings.Contains(string(out), "Usage:") {
+				t.Errorf("playlist %s %s: expected the usage, got %s", sub, flag, out)
+			}
+		}
+	}
+}
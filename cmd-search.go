@@ -0,0 +1,128 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"xkcd2/annotation"
+	"xkcd2/comic"
+)
+
+func init() {
+	commands["search"] = &command{
+		usage: "search [-size n] [-fuzzy] <query>",
+		help:  "searches the comics with a query, such as 'title:\"a phrase\" year:2015 -alt:math OR tag:security'",
+		run:   runSearch,
+	}
+}
+
+// runSearch lists the comics that match the query, see comic.ParseSearch. The annotation fields, such as
+// tag:security or rating:>=4, are also accepted. If nothing matches, it suggests a corrected query and
+// the comics with similar words. With -fuzzy the query is a list of words and the comics are ranked by
+// the similarity of their titles and alt texts.
+func runSearch(args []string) error {
+	fs := newFlagSet("search")
+	size := fs.Int("size", 0, "maximum number of comics listed, 0 lists all")
+	fuzzy := fs.Bool("fuzzy", false, "rank the comics by similar words in the title and alt text, tolerating typos")
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if fs.NArg() == 0 {
+		return fmt.Errorf("search: expected a query")
+	}
+
+	store, err := loadAnnotations()
+
+	if err != nil {
+		return err
+	}
+
+	text := strings.Join(fs.Args(), " ")
+
+	if *fuzzy {
+		matches := comic.NewFuzzyIndex(&comics).Search(text, *size)
+		printFuzzyMatches(matches, store)
+		fmt.Printf("\nFound: %d\n", len(matches))
+
+		return nil
+	}
+
+	filter, err := comic.ParseSearch(text, annotationField(store))
+
+	if err != nil {
+		return err
+	}
+
+	results := comics.Query().Filter(filter).Limit(*size).All()
+
+	for _, xkcd := range results {
+		printSearchResult(&xkcd, store.Get(xkcd.Number))
+	}
+
+	fmt.Printf("\nFound: %d\n", len(results))
+
+	if len(results) == 0 {
+		suggest(text, store)
+	}
+
+	return nil
+}
+
+// suggestions is the number of similar comics listed when a search finds nothing.
+const suggestions = 5
+
+// suggest writes a corrected query and the comics with words similar to the text terms of the query.
+func suggest(text string, store *annotation.Store) {
+	idx := comic.NewFuzzyIndex(&comics)
+
+	if correction := idx.DidYouMean(text); correction != "" {
+		fmt.Printf("Did you mean: %s\n", correction)
+	}
+
+	if matches := idx.Search(comic.FreeText(text), suggestions); len(matches) > 0 {
+		fmt.Println("\nSimilar comics:")
+		printFuzzyMatches(matches, store)
+	}
+}
+
+// printFuzzyMatches writes the comics found by a fuzzy search with their scores.
+func printFuzzyMatches(matches []comic.FuzzyMatch, store *annotation.Store) {
+	for _, match := range matches {
+		if xkcd := getComic(match.Number); xkcd != nil {
+			fmt.Printf("%.2f ", match.Score)
+			printSearchResult(xkcd, store.Get(match.Number))
+		}
+	}
+}
+
+// annotationField resolves the annotation fields of a search query, see annotation.ParseFilter.
+func annotationField(store *annotation.Store) comic.ExtraField {
+	return func(field, value string) (func(xkcd *comic.XKCD) bool, bool, error) {
+		match, ok, err := annotation.ParseFilter(field, value)
+
+		if err != nil || !ok {
+			return nil, ok, err
+		}
+
+		return func(xkcd *comic.XKCD) bool { return match(store.Get(xkcd.Number)) }, true, nil
+	}
+}
+
+// printSearchResult writes a comic found by search with its annotations.
+func printSearchResult(xkcd *comic.XKCD, a annotation.Annotation) {
+	date := ""
+
+	if d, err := xkcd.Date(); err == nil {
+		date = d.Format(comic.DateLayout)
+	}
+
+	fmt.Printf("%d,%s,%s", xkcd.Number, date, xkcd.Title)
+
+	if a.Updated.IsZero() {
+		fmt.Println()
+	} else {
+		fmt.Printf(" (%s)\n", formatAnnotation(a))
+	}
+}
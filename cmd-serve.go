@@ -0,0 +1,32 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+
+	"xkcd2/server"
+	"xkcd2/tools/util"
+)
+
+func init() {
+	commands["serve"] = &command{
+		usage: "serve [-addr address] [-site dir]",
+		help:  "serves the generated site, the feeds, the thumbnails and the playlists over HTTP",
+		run:   runServe,
+	}
+}
+
+// runServe starts the local web server and blocks until it fails.
+func runServe(args []string) error {
+	fs := newFlagSet("serve")
+	addr := fs.String("addr", "localhost:8080", "address to listen on")
+	siteDir := fs.String("site", util.GetSiteFolder(), "folder of the generated site")
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	fmt.Printf("Serving on http://%s/ (feeds at /atom.xml and /rss.xml, thumbnails at /thumbs/<size>/<n>.png, playlists at /playlists/)\n", *addr)
+
+	return http.ListenAndServe(*addr, server.New(&comics, *siteDir, thumbCache(), playlistStore()))
+}
@@ -0,0 +1,128 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"strconv"
+
+	"xkcd2/comic"
+	"xkcd2/persistence"
+)
+
+func init() {
+	commands["show"] = &command{
+		usage: "show [-asset role] [-o file] <n>",
+		help:  "shows a comic and its assets, or writes one of the assets to a file",
+		run:   runShow,
+	}
+}
+
+// runShow prints the details of comic n. With -o, the asset with the role given by -asset is written
+// to the file instead.
+func runShow(args []string) error {
+	fs := newFlagSet("show")
+	role := fs.String("asset", comic.AssetImage, "role of the asset written by -o: image, 2x or large")
+	out := fs.String("o", "", "write the asset to the file")
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if fs.NArg() != 1 {
+		return fmt.Errorf("show: expected one comic number")
+	}
+
+	comicNum, err := strconv.Atoi(fs.Arg(0))
+
+	if err != nil {
+		return fmt.Errorf("show: invalid comic number %q", fs.Arg(0))
+	}
+
+	_, xkcd := comics.Get(comicNum)
+
+	if xkcd == nil {
+		return fmt.Errorf("show: comic %d not found", comicNum)
+	}
+
+	if *out != "" {
+		data, err := assetData(xkcd, *role)
+
+		if err != nil {
+			return fmt.Errorf("show: %v", err)
+		}
+
+		if err := ioutil.WriteFile(*out, data, 0644); err != nil {
+			return fmt.Errorf("show: %v", err)
+		}
+
+		fmt.Printf("%s asset of comic %d written to %s\n", *role, comicNum, *out)
+
+		return nil
+	}
+
+	printComic(xkcd)
+
+	return nil
+}
+
+// printComic writes the details of the comic to the standard output.
+func printComic(xkcd *comic.XKCD) {
+	fmt.Printf("%d: %s\n", xkcd.Number, xkcd.Title)
+
+	if date, err := xkcd.Date(); err == nil {
+		fmt.Printf("Published: %s\n", date.Format(comic.DateLayout))
+	}
+
+	fmt.Printf("Alt: %s\n", xkcd.ImageAlt)
+
+	if xkcd.Link != "" {
+		fmt.Printf("Link: %s\n", xkcd.Link)
+	}
+
+	if transcript := xkcd.ParsedTranscript(); len(transcript.Panels) > 0 {
+		fmt.Printf("Transcript:\n\n%s\n", transcript)
+	}
+
+	fmt.Println("Assets:")
+
+	roles := []string{comic.AssetImage}
+
+	for _, asset := range xkcd.Assets {
+		roles = append(roles, asset.Role)
+	}
+
+	for _, role := range roles {
+		asset, ok := xkcd.Asset(role)
+
+		if !ok {
+			continue
+		}
+
+		stored := "not stored"
+
+		if (role == comic.AssetImage && xkcd.Image != "") || asset.Blob != "" {
+			stored = fmt.Sprintf("%d bytes", asset.Size)
+		}
+
+		fmt.Printf("  %-12s %-10s %-12s %s\n", asset.Role, asset.MIME, stored, asset.URL)
+	}
+}
+
+// assetData returns the stored content of the asset of xkcd with role.
+func assetData(xkcd *comic.XKCD, role string) ([]byte, error) {
+	if role == comic.AssetImage {
+		return xkcd.ImageData()
+	}
+
+	asset, ok := xkcd.Asset(role)
+
+	if !ok {
+		return nil, fmt.Errorf("comic %d has no %s asset", xkcd.Number, role)
+	}
+
+	if asset.Blob == "" {
+		return nil, fmt.Errorf("the %s asset of comic %d is not stored, see %s", role, xkcd.Number, asset.URL)
+	}
+
+	return persistence.ReadBlob(asset.Blob)
+}
@@ -0,0 +1,135 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+
+	"xkcd2/comic"
+	"xkcd2/tools/imaging"
+)
+
+func init() {
+	commands["similar"] = &command{
+		usage: "similar [-hash a|d|p] [-max distance] [-n count] <n>",
+		help:  "lists the comics that look like comic n",
+		run:   runSimilar,
+	}
+}
+
+// runSimilar finds the comics whose image hash is the nearest to the hash of comic n. The hashes missing
+// from the stored comics are computed first and written to the index file.
+func runSimilar(args []string) error {
+	fs := newFlagSet("similar")
+	kind := fs.String("hash", "p", "perceptual hash to compare: a (average), d (difference) or p (DCT)")
+	maxDistance := fs.Int("max", 10, "largest Hamming distance of a similar comic, from 0 to 64")
+	count := fs.Int("n", 10, "maximum number of comics listed")
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if fs.NArg() != 1 {
+		return fmt.Errorf("similar: expected one comic number")
+	}
+
+	comicNum, err := strconv.Atoi(fs.Arg(0))
+
+	if err != nil {
+		return fmt.Errorf("similar: invalid comic number %q", fs.Arg(0))
+	}
+
+	if _, ok := (imaging.Hashes{}).Get(*kind); !ok {
+		return fmt.Errorf("similar: unknown hash %q", *kind)
+	}
+
+	updateHashes()
+
+	_, target := comics.Get(comicNum)
+
+	if target == nil {
+		return fmt.Errorf("similar: comic %d not found", comicNum)
+	}
+
+	if target.Image == "" {
+		return fmt.Errorf("similar: comic %d has no stored image", comicNum)
+	}
+
+	tree, titles := hashIndex(*kind)
+	hash, _ := target.Hashes.Get(*kind)
+	listed := 0
+
+	for _, match := range tree.Search(hash, *maxDistance) {
+		if match.ID == comicNum {
+			continue
+		}
+
+		if listed == *count {
+			break
+		}
+
+		fmt.Printf("%d\t%d\t%s\n", match.ID, match.Distance, titles[match.ID])
+		listed++
+	}
+
+	if listed == 0 {
+		fmt.Printf("No comic within distance %d of comic %d\n", *maxDistance, comicNum)
+	}
+
+	return nil
+}
+
+// updateHashes computes the perceptual hashes of the stored images that do not have them yet and
+// writes the index file if any were computed. The images that cannot be decoded are reported.
+func updateHashes() {
+	var missing []int
+
+	comics.Query().Filter(func(xkcd *comic.XKCD) bool {
+		return xkcd.Image != "" && xkcd.Hashes == (imaging.Hashes{})
+	}).Each(func(xkcd comic.XKCD) bool {
+		missing = append(missing, xkcd.Number)
+		return true
+	})
+
+	if len(missing) == 0 {
+		return
+	}
+
+	fmt.Printf("Computing image hashes of %d comics\n", len(missing))
+
+	computed := 0
+
+	for _, num := range missing {
+		comics.Update(num, func(xkcd *comic.XKCD) {
+			if err := xkcd.ComputeHashes(); err != nil {
+				fmt.Printf("%d: %v\n", num, err)
+				return
+			}
+
+			computed++
+		})
+	}
+
+	if computed > 0 {
+		writeComics()
+	}
+}
+
+// hashIndex builds a BK-tree of the kind of hash of the stored images and returns it with the titles of the comics.
+func hashIndex(kind string) (*imaging.BKTree, map[int]string) {
+	tree := &imaging.BKTree{}
+	titles := make(map[int]string)
+
+	comics.Query().Each(func(xkcd comic.XKCD) bool {
+		if xkcd.Image == "" || xkcd.Hashes == (imaging.Hashes{}) {
+			return true
+		}
+
+		hash, _ := xkcd.Hashes.Get(kind)
+		tree.Add(hash, xkcd.Number)
+		titles[xkcd.Number] = xkcd.Title
+
+		return true
+	})
+
+	return tree, titles
+}
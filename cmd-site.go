@@ -0,0 +1,40 @@
+package main
+
+import (
+	"fmt"
+
+	"xkcd2/persistence"
+	"xkcd2/site"
+	"xkcd2/tools/util"
+)
+
+func init() {
+	commands["site"] = &command{
+		usage: "site [-o dir] [-asset role]",
+		help:  "renders the offline collection into a static web site",
+		run:   runSite,
+	}
+}
+
+// runSite generates the static web site from the loaded comics.
+func runSite(args []string) error {
+	fs := newFlagSet("site")
+	out := fs.String("o", util.GetSiteFolder(), "output folder of the generated site")
+	asset := fs.String("asset", "", "role of the asset shown instead of the image when stored, for example 2x")
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	all := comics.GetAll()
+
+	options := site.Options{Asset: *asset, ReadBlob: persistence.ReadBlob}
+
+	if err := site.GenerateWith(*out, all, options); err != nil {
+		return err
+	}
+
+	fmt.Printf("Site with %d comics written to %s\n", len(all), *out)
+
+	return nil
+}
@@ -0,0 +1,165 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"xkcd2/comic"
+)
+
+func init() {
+	commands["sync"] = &command{
+		usage: "sync [-check-updates] [-recent n] [-all] [-dry-run]",
+		help:  "syncs the offline index, optionally checking stored comics for changes on the web site",
+		run:   runSync,
+	}
+}
+
+// updateResult is the outcome of checking a single stored comic for changes on the web site.
+type updateResult struct {
+	comicNum int
+	xkcd     *comic.XKCD // nil if the comic has not changed
+	changes  []comic.FieldChange
+	err      error
+}
+
+// runSync downloads the missing comics, like running the utility without a command. With -check-updates,
+// it also checks the stored comics for changes and applies them before the index file is written.
+func runSync(args []string) error {
+	fs := newFlagSet("sync")
+	check := fs.Bool("check-updates", false, "check the stored comics for changes on the web site")
+	recent := fs.Int("recent", 50, "number of the latest comics to check (used only with -check-updates)")
+	all := fs.Bool("all", false, "check all the stored comics (used only with -check-updates)")
+	dryRun := fs.Bool("dry-run", false, "report the changes without applying them (used only with -check-updates)")
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	start := time.Now()
+	doSync()
+
+	if *check {
+		if *all {
+			*recent = 0
+		}
+
+		results := checkUpdates(*recent)
+		reportUpdates(results)
+
+		if *dryRun {
+			fmt.Println("Dry run, no changes applied")
+		} else {
+			applyUpdates(results)
+		}
+	}
+
+	finishSync(start)
+
+	return nil
+}
+
+// checkUpdates checks the latest recent stored comics, or all of them if recent is 0, for changes on
+// the web site. Conditional requests are used, so the unchanged comics are not downloaded again.
+func checkUpdates(recent int) []updateResult {
+	stored := comics.Query().OrderBy(comic.OrderByNumber, true).Limit(recent).All()
+	resultChan := make(chan updateResult)
+
+	// counting semaphore that limits the number of concurrent downloads, the same as in fetchComics
+	semaphore := make(chan struct{}, 20)
+
+	for i := range stored {
+		go func(xkcd *comic.XKCD) {
+			semaphore <- struct{}{}
+			defer func() { <-semaphore }()
+
+			fresh, err := xkcd.CheckUpdate()
+			result := updateResult{comicNum: xkcd.Number, xkcd: fresh, err: err}
+
+			if fresh != nil {
+				refreshed := comic.Refresh(xkcd, fresh)
+				result.changes = comic.UpstreamDiff(xkcd, &refreshed)
+			}
+
+			resultChan <- result
+		}(&stored[i])
+	}
+
+	results := make([]updateResult, 0, len(stored))
+
+	for range stored {
+		results = append(results, <-resultChan)
+	}
+
+	return results
+}
+
+// reportUpdates prints the changed comics and the failed checks.
+func reportUpdates(results []updateResult) {
+	changed, failed := 0, 0
+
+	for _, result := range results {
+		switch {
+		case result.err != nil:
+			failed++
+			fmt.Printf("\n%d: check failed: %v\n", result.comicNum, result.err)
+		case len(result.changes) > 0:
+			changed++
+			fmt.Printf("\n%d: updated\n", result.comicNum)
+
+			for _, change := range result.changes {
+				fmt.Printf("  %s: %q -> %q\n", change.Field, change.Old, change.New)
+			}
+		}
+	}
+
+	fmt.Printf("\nChecked: %d, changed: %d, failed: %d\n", len(results), changed, failed)
+}
+
+// applyUpdates replaces the stored comics by the downloaded ones, see comic.Refresh, which records the
+// changes in their history. A comic whose image URL changed loses its stored image and thumbnails, and
+// the new image is downloaded.
+func applyUpdates(results []updateResult) {
+	var newImages []int
+
+	for _, result := range results {
+		if result.xkcd == nil {
+			continue
+		}
+
+		// also stores the new validators of unchanged records
+		comics.Refresh(result.xkcd)
+
+		for _, change := range result.changes {
+			if change.Field == "ImageURL" {
+				newImages = append(newImages, result.comicNum)
+
+				if err := thumbCache().Invalidate(result.comicNum); err != nil {
+					log.Println(err)
+				}
+			}
+		}
+	}
+
+	if len(newImages) == 0 {
+		return
+	}
+
+	fmt.Printf("\nDownloading %d changed images\n", len(newImages))
+	failed := 0
+
+	for _, result := range downloadImages(newImages) {
+		if result.err != nil {
+			failed++
+			fmt.Printf("%d: image download failed: %v\n", result.comicNum, result.err)
+			continue
+		}
+
+		storeImage(result)
+	}
+
+	if failed > 0 {
+		fmt.Printf("Run verify -images -missing to retry the %d failed images\n", failed)
+	}
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+
+	"xkcd2/comic"
+	"xkcd2/config"
+	"xkcd2/tools/imaging"
+	"xkcd2/tools/util"
+)
+
+func init() {
+	commands["thumbs"] = &command{
+		usage: "thumbs [-size n] [-from n] [-to n] [n ...]",
+		help:  "generates thumbnails of the stored images into the thumbnail cache",
+		run:   runThumbs,
+	}
+}
+
+// thumbCache returns the cache of thumbnails in the XKCD folder.
+func thumbCache() *imaging.ThumbCache {
+	return imaging.NewThumbCache(util.GetThumbsFolder())
+}
+
+// runThumbs generates the thumbnails of the given comics, or of all the comics in the range, and prints
+// their filenames. Thumbnails already in the cache are not generated again.
+func runThumbs(args []string) error {
+	fs := newFlagSet("thumbs")
+	size := fs.Int("size", config.ThumbSize, fmt.Sprintf("size in pixels of the longer side of the thumbnails, one of %v", config.ThumbSizes))
+	from := fs.Int("from", 0, "first comic number")
+	to := fs.Int("to", 0, "last comic number")
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if !validThumbSize(*size) {
+		return fmt.Errorf("thumbs: size must be one of %v", config.ThumbSizes)
+	}
+
+	var items []comic.XKCD
+
+	if fs.NArg() > 0 {
+		for _, arg := range fs.Args() {
+			num, err := strconv.Atoi(arg)
+
+			if err != nil {
+				return fmt.Errorf("thumbs: invalid comic number %q", arg)
+			}
+
+			_, xkcd := comics.Get(num)
+
+			if xkcd == nil {
+				return fmt.Errorf("thumbs: comic %d not found", num)
+			}
+
+			items = append(items, *xkcd)
+		}
+	} else {
+		items = comics.Query().Range(*from, *to).All()
+	}
+
+	cache := thumbCache()
+	generated, skipped := 0, 0
+
+	for _, xkcd := range items {
+		if _, err := cache.Get(*size, xkcd.Number, xkcd.ImageData); err != nil {
+			if err != comic.ErrNoImage {
+				fmt.Printf("%d: %v\n", xkcd.Number, err)
+			}
+
+			skipped++
+			continue
+		}
+
+		generated++
+		fmt.Println(cache.Path(*size, xkcd.Number))
+	}
+
+	fmt.Printf("\nThumbnails: %d, skipped: %d\n", generated, skipped)
+
+	return nil
+}
+
+// validThumbSize returns true if size is one of config.ThumbSizes, the sizes the server returns.
+func validThumbSize(size int) bool {
+	for _, valid := range config.ThumbSizes {
+		if size == valid {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"fmt"
+
+	"xkcd2/comic"
+)
+
+func init() {
+	commands["verify"] = &command{
+		usage: "verify -images [-missing] [-dry-run]",
+		help:  "reports the corrupt stored images and downloads them again",
+		run:   runVerify,
+	}
+}
+
+// runVerify checks the stored images against the recorded size and checksum and decodes them. The corrupt
+// images are downloaded again and the index file is written if any of them was replaced.
+func runVerify(args []string) error {
+	fs := newFlagSet("verify")
+	images := fs.Bool("images", false, "verify the stored images")
+	missing := fs.Bool("missing", false, "also download the images of the comics without a stored image")
+	dryRun := fs.Bool("dry-run", false, "report the corrupt images without downloading them")
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if !*images {
+		return fmt.Errorf("verify: nothing to verify, use -images")
+	}
+
+	var refetch []int
+	verified, absent := 0, 0
+
+	comics.Query().Each(func(xkcd comic.XKCD) bool {
+		err := xkcd.VerifyImage()
+
+		switch {
+		case err == nil:
+			verified++
+		case err == comic.ErrNoImage:
+			absent++
+
+			if *missing {
+				refetch = append(refetch, xkcd.Number)
+			}
+		default:
+			fmt.Printf("%d: %v\n", xkcd.Number, err)
+			refetch = append(refetch, xkcd.Number)
+		}
+
+		return true
+	})
+
+	fmt.Printf("\nVerified: %d, without image: %d, to download: %d\n", verified, absent, len(refetch))
+
+	if *dryRun || len(refetch) == 0 {
+		return nil
+	}
+
+	stored := 0
+
+	for _, result := range downloadImages(refetch) {
+		if result.err != nil {
+			fmt.Printf("%d: download failed: %v\n", result.comicNum, result.err)
+			continue
+		}
+
+		storeImage(result)
+		stored++
+	}
+
+	fmt.Printf("\nDownloaded images: %d, failed: %d\n", stored, len(refetch)-stored)
+
+	if stored > 0 {
+		writeComics()
+	}
+
+	return nil
+}
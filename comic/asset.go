@@ -0,0 +1,141 @@
+package comic
+
+import (
+	"fmt"
+	"mime"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+
+	"xkcd2/tools/logger"
+)
+
+// Roles of the assets of a comic.
+const (
+	AssetImage       = "image"       // the image at ImageURL, stored in XKCD.Image
+	Asset2x          = "2x"          // high resolution variant of the image
+	AssetLarge       = "large"       // large clickable version linked from the comic
+	AssetInteractive = "interactive" // interactive page linked from the comic, it is not downloaded
+)
+
+// Asset is a file that belongs to a comic. The downloaded content is kept in the blob store and
+// referenced by Blob, an empty Blob means that the asset was not downloaded.
+type Asset struct {
+	Role string
+	URL  string
+	MIME string
+	Size int
+	Blob string
+}
+
+// Asset returns the asset of the comic with role. The image asset is built from the Image fields,
+// since the image is stored in the record itself.
+func (xkcd *XKCD) Asset(role string) (Asset, bool) {
+	if role == AssetImage {
+		return Asset{Role: AssetImage, URL: xkcd.ImageURL, MIME: mimeType(xkcd.ImageURL), Size: xkcd.ImageSize},
+			xkcd.ImageURL != ""
+	}
+
+	for _, asset := range xkcd.Assets {
+		if asset.Role == role {
+			return asset, true
+		}
+	}
+
+	return Asset{}, false
+}
+
+// SetAsset adds the asset to the comic, replacing the asset with the same role.
+func (xkcd *XKCD) SetAsset(asset Asset) {
+	for i := range xkcd.Assets {
+		if xkcd.Assets[i].Role == asset.Role {
+			xkcd.Assets[i] = asset
+			return
+		}
+	}
+
+	xkcd.Assets = append(xkcd.Assets, asset)
+}
+
+// DiscoverAssets returns the variants of the comic that can be derived from its record, without their content:
+// the _2x image next to ImageURL, the large version when Link points to an image and the interactive page
+// when Link points elsewhere. Not every comic has a _2x image, so the candidate may not exist.
+func DiscoverAssets(xkcd *XKCD) []Asset {
+	var result []Asset
+
+	if ext := path.Ext(xkcd.ImageURL); ext != "" && !strings.HasSuffix(strings.TrimSuffix(xkcd.ImageURL, ext), "_2x") {
+		url2x := strings.TrimSuffix(xkcd.ImageURL, ext) + "_2x" + ext
+		result = append(result, Asset{Role: Asset2x, URL: url2x, MIME: mimeType(url2x)})
+	}
+
+	if link := strings.TrimSpace(xkcd.Link); link != "" {
+		if mimeType := mimeType(link); strings.HasPrefix(mimeType, "image/") {
+			result = append(result, Asset{Role: AssetLarge, URL: link, MIME: mimeType})
+		} else {
+			result = append(result, Asset{Role: AssetInteractive, URL: link, MIME: "text/html"})
+		}
+	}
+
+	return result
+}
+
+// DownloadAssets downloads the discovered image variants of the comic that are not downloaded yet
+// and adds them to Assets. The content is saved by store, which returns the reference of the blob.
+// Candidates that do not exist on the web site are skipped, the interactive pages are only recorded.
+func (xkcd *XKCD) DownloadAssets(store func(data []byte) (string, error)) error {
+	defer logger.Trace(fmt.Sprintf("func DownloadAssets(%d)", xkcd.Number))()
+
+	for _, asset := range DiscoverAssets(xkcd) {
+		if existing, ok := xkcd.Asset(asset.Role); ok && (existing.Blob != "" || asset.Role == AssetInteractive) {
+			continue
+		}
+
+		if asset.Role == AssetInteractive {
+			xkcd.SetAsset(asset)
+			continue
+		}
+
+		data, err := downloadImage(asset.URL)
+
+		if err != nil {
+			logger.Info(fmt.Sprintf("DownloadAssets(%s): %v", asset.URL, err))
+			continue
+		}
+
+		detected := http.DetectContentType(data)
+
+		if !strings.HasPrefix(detected, "image/") {
+			logger.Info(fmt.Sprintf("DownloadAssets(%s): not an image, %s", asset.URL, detected))
+			continue
+		}
+
+		ref, err := store(data)
+
+		if err != nil {
+			return fmt.Errorf("download assets: %v", err)
+		}
+
+		asset.MIME, asset.Size, asset.Blob = detected, len(data), ref
+		xkcd.SetAsset(asset)
+	}
+
+	return nil
+}
+
+// mimeType returns the MIME type by the extension of the file in rawURL, or an empty string.
+func mimeType(rawURL string) string {
+	u, err := url.Parse(rawURL)
+
+	if err != nil {
+		return ""
+	}
+
+	result := mime.TypeByExtension(path.Ext(u.Path))
+
+	if i := strings.Index(result, ";"); i >= 0 {
+		result = result[:i]
+	}
+
+	return result
+}
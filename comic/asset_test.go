@@ -0,0 +1,102 @@
+package comic
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"testing"
+
+	"xkcd2/webclient"
+	"xkcd2/webclient/mocks"
+)
+
+func TestDiscoverAssets(t *testing.T) {
+	tests := []struct {
+		xkcd XKCD
+		want []Asset
+	}{
+		{
+			XKCD{ImageURL: "https://imgs.xkcd.com/comics/barrel_cropped_(1).jpg"},
+			[]Asset{{Role: Asset2x, URL: "https://imgs.xkcd.com/comics/barrel_cropped_(1)_2x.jpg", MIME: "image/jpeg"}},
+		},
+		{
+			XKCD{ImageURL: "https://imgs.xkcd.com/comics/a_2x.png", Link: "https://imgs.xkcd.com/comics/a_large.png"},
+			[]Asset{{Role: AssetLarge, URL: "https://imgs.xkcd.com/comics/a_large.png", MIME: "image/png"}},
+		},
+		{
+			XKCD{Link: "https://xkcd.com/1190/"},
+			[]Asset{{Role: AssetInteractive, URL: "https://xkcd.com/1190/", MIME: "text/html"}},
+		},
+	}
+
+	for _, tt := range tests {
+		got := DiscoverAssets(&tt.xkcd)
+
+		if fmt.Sprint(got) != fmt.Sprint(tt.want) {
+			t.Errorf("expected %v, got %v", tt.want, got)
+		}
+	}
+}
+
+func TestDownloadAssets(t *testing.T) {
+	saved := webclient.Client
+	defer func() { webclient.Client = saved }()
+
+	png := []byte("\x89PNG\r\n\x1a\n-image-")
+
+	webclient.Client = &mocks.MockClient{}
+	mocks.GetDoFunc = func(req *http.Request) (*http.Response, error) {
+		if req.URL.Path == "/comics/b_2x.png" {
+			return &http.Response{StatusCode: http.StatusNotFound, Status: "404 Not Found", Body: ioutil.NopCloser(bytes.NewReader(nil))}, nil
+		}
+
+		return &http.Response{StatusCode: http.StatusOK, Body: ioutil.NopCloser(bytes.NewReader(png))}, nil
+	}
+
+	var stored [][]byte
+	store := func(data []byte) (string, error) {
+		stored = append(stored, data)
+		return fmt.Sprintf("blob%d", len(stored)), nil
+	}
+
+	xkcd := &XKCD{Number: 1, ImageURL: "https://imgs.xkcd.com/comics/a.png", Link: "https://imgs.xkcd.com/comics/a_large.png"}
+
+	if err := xkcd.DownloadAssets(store); err != nil {
+		t.Fatal(err)
+	}
+
+	asset2x, ok2x := xkcd.Asset(Asset2x)
+	large, okLarge := xkcd.Asset(AssetLarge)
+
+	if !ok2x || !okLarge || asset2x.Blob != "blob1" || large.Blob != "blob2" || large.MIME != "image/png" || large.Size != len(png) {
+		t.Errorf("expected both variants to be stored, got %+v", xkcd.Assets)
+	}
+
+	// assets already downloaded are skipped
+	xkcd.DownloadAssets(store)
+
+	if len(stored) != 2 {
+		t.Errorf("expected 2 stored blobs, got %d", len(stored))
+	}
+
+	// a missing _2x variant is skipped
+	missing := &XKCD{Number: 2, ImageURL: "https://imgs.xkcd.com/comics/b.png"}
+
+	if err := missing.DownloadAssets(store); err != nil || len(missing.Assets) != 0 {
+		t.Errorf("expected no assets, got %+v, %v", missing.Assets, err)
+	}
+}
+
+func TestGetDoesNotShareAssets(t *testing.T) {
+	var c Comics
+
+	c.Add(&XKCD{Number: 1, Assets: []Asset{{Role: Asset2x, Blob: "a"}}})
+
+	_, xkcd := c.Get(1)
+	xkcd.SetAsset(Asset{Role: Asset2x, Blob: "b"})
+
+	if _, stored := c.Get(1); stored.Assets[0].Blob != "a" {
+		t.Errorf("expected the stored asset not to change, got %+v", stored.Assets)
+	}
+}
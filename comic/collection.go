@@ -7,41 +7,96 @@ import (
 	"xkcd2/tools/logger"
 )
 
+// Comics is a collection of XKCD comics. Every comic is stored once, mapped by its number, so looking
+// up a comic does not depend on the order in which the comics were added. The numbers are also kept in
+// ascending order which is the ordered view of the collection: comics added out of order are inserted
+// at their position, so reading the collection never reorders it. The stored comics are never handed out,
+// Get and GetAll return copies and Update changes a stored comic under the lock. All the methods are
+// safe for concurrent use.
 type Comics struct {
-	mu     sync.Mutex
-	comics []XKCD
-	sorted bool
+	mu      sync.RWMutex
+	comics  map[int]*XKCD // comic number -> comic
+	numbers []int         // comic numbers in ascending order
+	history map[int][]Revision
 }
 
-// Load adds a list of XKCD objects to the internal collection.
-// When the data is loaded, an assumption is made that the items are sorted,
-// therefore, prior to writing the comics to the disk, make sure to call Sort() method.
-// This method should not be run as a goroutine while crawling and calling Add() method
-// as it might change the state of the collection.
+// Load adds a list of XKCD objects to the internal collection. The items are copied and loaded only into
+// an empty collection. The items do not have to be sorted. If the same comic number appears more than once,
+// the items are merged, see Dedup.
 func (c *Comics) Load(items []XKCD) {
 	defer logger.Trace("func LoadComics")()
 
-	if c.Len() == 0 {
-		c.comics = make([]XKCD, 0, len(items))
-		c.comics = append(c.comics, items...)
-		c.sorted = true
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if len(c.comics) > 0 {
+		return
+	}
+
+	unique, _ := Dedup(items)
+
+	c.comics = make(map[int]*XKCD, len(unique))
+	c.numbers = make([]int, 0, len(unique))
+
+	for i := range unique {
+		unique[i] = unique[i].clone()
+		c.comics[unique[i].Number] = &unique[i]
+		c.numbers = append(c.numbers, unique[i].Number)
 	}
 }
 
 // Add will insert xkcd into a collection of comics. Add uses a mutex to add an item
-// in order to prevent concurrent access to the collection.
+// in order to prevent concurrent access to the collection. If the collection already contains
+// a comic with the same number, xkcd is merged into it (see Merge), so Add never creates duplicates.
+// The fields changed by the merge are recorded in the history of the comic, see History.
 func (c *Comics) Add(xkcd *XKCD) {
 	defer logger.Trace("method Add()")()
 
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
-	size := c.Len()
-	if c.sorted && size > 0 && c.comics[size-1].Number > xkcd.Number {
-		c.sorted = false
+	if c.comics == nil {
+		c.comics = make(map[int]*XKCD)
+	}
+
+	if existing, ok := c.comics[xkcd.Number]; ok {
+		merged := Merge(existing, xkcd)
+		merged = merged.clone()
+		c.recordRevision(existing, &merged)
+		*existing = merged
+		return
+	}
+
+	item := xkcd.clone()
+	c.comics[xkcd.Number] = &item
+	c.insertNumber(xkcd.Number)
+}
+
+// Refresh replaces a stored comic by xkcd downloaded again from the web site, see Refresh, and records the
+// changed fields in the history of the comic. A comic that is not stored yet is added.
+func (c *Comics) Refresh(xkcd *XKCD) {
+	defer logger.Trace("method Refresh()")()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	existing, ok := c.comics[xkcd.Number]
+
+	if !ok {
+		if c.comics == nil {
+			c.comics = make(map[int]*XKCD)
+		}
+
+		item := xkcd.clone()
+		c.comics[xkcd.Number] = &item
+		c.insertNumber(xkcd.Number)
+
+		return
 	}
 
-	c.comics = append(c.comics, *xkcd)
+	refreshed := Refresh(existing, xkcd)
+	c.recordChanges(xkcd.Number, UpstreamDiff(existing, &refreshed))
+	*existing = refreshed
 }
 
 // Contains return true or false depending on whether it found a comicNum in the collection
@@ -54,30 +109,53 @@ func (c *Comics) Contains(comicNum int) bool {
 	return index > -1
 }
 
-// Get returns a comic and the index where the comicNum was found. If a comic is not found, the return value is -1 for index and nil for XKCD type
+// Get returns a comic and the index where the comicNum was found. If a comic is not found, the return value is -1 for index and nil for XKCD type.
+// The index is the position in the ordered view of the collection, it changes when a comic with a lower number is added or removed.
+// The returned comic is a copy owned by the caller, changing it does not change the collection. Use Update to change a stored comic.
 func (c *Comics) Get(comicNum int) (int, *XKCD) {
 	defer logger.Trace("method Get()")()
 
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	xkcd, ok := c.comics[comicNum]
+
+	if !ok {
+		return -1, nil
+	}
+
+	result := xkcd.clone()
+
+	return sort.SearchInts(c.numbers, comicNum), &result
+}
+
+// Update calls change with the stored comic comicNum while holding the lock, so the change is safe for
+// concurrent use. change must not call the methods of the collection and must not keep the pointer.
+// The comic number cannot be changed. Update returns false if the comic is not in the collection.
+func (c *Comics) Update(comicNum int, change func(xkcd *XKCD)) bool {
+	defer logger.Trace("method Update()")()
+
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
-	index := -1
-	var xkcd *XKCD
+	xkcd, ok := c.comics[comicNum]
 
-	if c.sorted {
-		index, xkcd = c.getBinarySearch(0, len(c.comics)-1, comicNum)
-	} else {
-		index, xkcd = c.getSequentialSearch(comicNum)
+	if !ok {
+		return false
 	}
 
-	return index, xkcd
+	change(xkcd)
+	xkcd.Number = comicNum
+
+	return true
 }
 
-// GetAll returns the entire collection of Comics
+// GetAll returns a snapshot of the entire collection of Comics ordered by the comic number.
+// The returned slice is a copy, so it is not affected by the later changes to the collection.
 func (c *Comics) GetAll() []XKCD {
 	defer logger.Trace("func GetComics")()
 
-	return c.comics
+	return c.snapshot()
 }
 
 // Index returns index number of a collection or -1 if not found. See Get func.
@@ -91,35 +169,27 @@ func (c *Comics) Index(comicNum int) int {
 func (c *Comics) Remove(index int) bool {
 	defer logger.Trace("method Remove()")()
 
-	if index == -1 || index >= len(c.comics) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if index < 0 || index >= len(c.numbers) {
 		return false
 	}
 
-	copy(c.comics[index:], c.comics[index+1:])
-	c.comics = c.comics[:len(c.comics)-1]
+	delete(c.comics, c.numbers[index])
+	c.numbers = append(c.numbers[:index], c.numbers[index+1:]...)
 
 	return true
 }
 
-// Sort will sort the comics in ascending order based on the comic number
+// Sort is kept for compatibility. The collection is always ordered by the comic number.
 func (c *Comics) Sort() {
-	c.mu.Lock()
-	defer c.mu.Unlock()
-	sort.Sort(c)
-	c.sorted = true
 }
 
-// Len returns a len value of the collection slice. It is part of the sort packages Interface type.
+// Len returns the number of comics in the collection.
 func (c *Comics) Len() int {
-	return len(c.comics)
-}
-
-// Less compares i and j and returns bool if i is smaller than j.
-func (c *Comics) Less(i, j int) bool {
-	return c.comics[i].Number < c.comics[j].Number
-}
+	c.mu.RLock()
+	defer c.mu.RUnlock()
 
-// Swap replaces the position of two items in the collection
-func (c *Comics) Swap(i, j int) {
-	c.comics[i], c.comics[j] = c.comics[j], c.comics[i]
+	return len(c.numbers)
 }
@@ -2,8 +2,15 @@ package comic
 
 import "testing"
 
-var c2k = Comics{comics: setupComics(20000, false)}
-var c2krand = Comics{comics: setupComics(20000, true), sorted: false}
+var c2k = loadComics(setupComics(20000, false))
+var c2krand = loadComics(setupComics(20000, true))
+
+func loadComics(items []XKCD) *Comics {
+	c := &Comics{}
+	c.Load(items)
+
+	return c
+}
 
 func benchmarkLoading(comics []XKCD, count int, b *testing.B) {
 	c := Comics{}
@@ -86,3 +93,77 @@ func BenchmarkComicGetFromRandomSource(b *testing.B) {
 		c2krand.Get(12534)
 	}
 }
+
+// reversed returns count comics ordered from the highest to the lowest number, which is the worst case
+// for a collection that relies on comics arriving in order.
+func reversed(count int) []*XKCD {
+	result := make([]*XKCD, 0, count)
+
+	for i := count; i > 0; i-- {
+		result = append(result, &XKCD{Number: i, Title: "Unit test"})
+	}
+
+	return result
+}
+
+func BenchmarkComicAddUnordered(b *testing.B) {
+	items := reversed(2000)
+
+	for n := 0; n < b.N; n++ {
+		c := Comics{}
+
+		for _, item := range items {
+			c.Add(item)
+		}
+	}
+}
+
+func BenchmarkComicContainsAfterUnorderedAdd(b *testing.B) {
+	c := Comics{}
+
+	for _, item := range reversed(2000) {
+		c.Add(item)
+	}
+
+	b.ResetTimer()
+
+	for n := 0; n < b.N; n++ {
+		c.Contains(n%2000 + 1)
+	}
+}
+
+func BenchmarkComicGetRandom(b *testing.B) {
+	for n := 0; n < b.N; n++ {
+		c2krand.Get(n%20000 + 1)
+	}
+}
+
+func BenchmarkComicGetAllAfterUnorderedAdd(b *testing.B) {
+	items := reversed(2000)
+
+	for n := 0; n < b.N; n++ {
+		c := Comics{}
+
+		for _, item := range items {
+			c.Add(item)
+		}
+
+		c.GetAll()
+	}
+}
+
+// BenchmarkComicSyncPattern mimics fetchComics where every number is checked with Contains
+// before the downloaded comic is added out of order.
+func BenchmarkComicSyncPattern(b *testing.B) {
+	items := reversed(2000)
+
+	for n := 0; n < b.N; n++ {
+		c := Comics{}
+
+		for _, item := range items {
+			if !c.Contains(item.Number) {
+				c.Add(item)
+			}
+		}
+	}
+}
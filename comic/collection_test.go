@@ -3,6 +3,7 @@ package comic
 import (
 	"fmt"
 	"math/rand"
+	"sync"
 	"testing"
 	"time"
 )
@@ -62,15 +63,11 @@ func TestComicsAddUnordered(t *testing.T) {
 	var xkcd10 = &XKCD{Number: 10, Title: "Adding Title"}
 	var xkcd9 = &XKCD{Number: 9, Title: "Adding Title"}
 
-	c.sorted = true
-
 	c.Add(xkcd10)
 	c.Add(xkcd9)
 
-	got := c.sorted
-
-	if got {
-		t.Errorf("wanted sorted=false, got %t", got)
+	if c.numbers[0] != 9 || c.numbers[1] != 10 {
+		t.Errorf("wanted [9 10], got %v", c.numbers)
 	}
 }
 
@@ -210,22 +207,267 @@ func TestRemoveOverLength(t *testing.T) {
 func TestSort(t *testing.T) {
 	c := Comics{}
 	c.Load(setupComics(1000, true))
-	c.sorted = false // input is randomized so we need to change to false
-
-	want := c.sorted
 	c.Sort()
-	got := c.sorted
 
-	if want == got {
-		t.Errorf("expected %t, got %t", want, got)
-	}
+	all := c.GetAll()
 
-	for i := 1; i < c.Len(); i++ {
-		prev := c.comics[i-1].Number
-		curr := c.comics[i].Number
+	for i := 1; i < len(all); i++ {
+		prev := all[i-1].Number
+		curr := all[i].Number
 
 		if prev > curr {
 			t.Errorf("expected %d, got %d", curr, prev)
 		}
 	}
 }
+
+func TestComicsGetAfterUnorderedAdd(t *testing.T) {
+	c := Comics{}
+
+	for _, num := range []int{5, 3, 9, 1} {
+		c.Add(&XKCD{Number: num})
+	}
+
+	for _, num := range []int{1, 3, 5, 9} {
+		if !c.Contains(num) {
+			t.Errorf("expected %d to exist", num)
+		}
+	}
+
+	if c.Contains(2) {
+		t.Errorf("expected 2 not to exist")
+	}
+
+	all := c.GetAll()
+
+	for i := 1; i < len(all); i++ {
+		if all[i-1].Number > all[i].Number {
+			t.Errorf("expected ordered comics, got %d before %d", all[i-1].Number, all[i].Number)
+		}
+	}
+
+	i, xkcd := c.Get(9)
+
+	if i != 3 || xkcd == nil || xkcd.Number != 9 {
+		t.Errorf("expected comic 9 at index 3, got %d", i)
+	}
+}
+
+func TestComicsLoadUnordered(t *testing.T) {
+	c := Comics{}
+	c.Load([]XKCD{{Number: 3}, {Number: 1}, {Number: 2}})
+
+	if i, _ := c.Get(1); i != 0 {
+		t.Errorf("expected 1 at index 0, got %d", i)
+	}
+}
+
+func TestComicsAddDuplicate(t *testing.T) {
+	c := Comics{}
+	c.Load(setupComics(3, false))
+
+	c.Add(&XKCD{Number: 2, Title: "Replaced"})
+
+	if c.Len() != 3 {
+		t.Errorf("expected 3, got %d", c.Len())
+	}
+
+	c.Remove(c.Index(2))
+
+	for _, xkcd := range c.GetAll() {
+		if xkcd.Number == 2 {
+			t.Errorf("expected 2 to be removed from the ordered view")
+		}
+	}
+}
+
+func TestComicsIndexStableAfterGetAll(t *testing.T) {
+	c := Comics{}
+
+	for _, num := range []int{5, 3, 9, 1} {
+		c.Add(&XKCD{Number: num})
+	}
+
+	i := c.Index(5)
+	c.GetAll()
+	c.Remove(i)
+
+	if c.Contains(5) || c.Len() != 3 {
+		t.Errorf("expected 5 to be removed, got %v", c.numbers)
+	}
+}
+
+func TestRemoveKeepsIndex(t *testing.T) {
+	c := Comics{}
+	c.Load(setupComics(10, false))
+
+	c.Remove(c.Index(5))
+
+	if c.Contains(5) {
+		t.Errorf("expected 5 to be removed")
+	}
+
+	if i := c.Index(6); i != 4 {
+		t.Errorf("expected 6 at index 4, got %d", i)
+	}
+}
+
+// TestComicsConcurrentAccess calls every method of the collection from several goroutines at the same time.
+// Run with the -race flag to detect unsynchronized access. The methods that copy the whole collection run
+// only every few iterations to keep the test fast under the race detector.
+func TestComicsConcurrentAccess(t *testing.T) {
+	c := Comics{}
+	c.Load(setupComics(100, false))
+
+	const workers = 4
+	const iterations = 100
+
+	var wg sync.WaitGroup
+
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+
+		go func(w int) {
+			defer wg.Done()
+
+			for i := 0; i < iterations; i++ {
+				num := 1000 + w*iterations + i
+
+				c.Add(&XKCD{Number: num, Title: "Concurrent"})
+				c.Contains(num)
+				c.Index(num)
+				c.Len()
+				c.Update(i%100+1, func(xkcd *XKCD) { xkcd.News = "updated" })
+
+				if _, xkcd := c.Get(i%100 + 1); xkcd != nil {
+					xkcd.Title = "changed copy"
+				}
+
+				if i%20 == 0 {
+					c.Remove(c.Index(num))
+				}
+
+				if i%25 == 0 {
+					c.FilterByDate(func(time.Time) bool { return true })
+					c.Missing(0)
+
+					if all := c.GetAll(); len(all) > 0 {
+						all[0].Title = "changed snapshot"
+					}
+				}
+			}
+		}(w)
+	}
+
+	wg.Wait()
+
+	want := 100 + workers*iterations - workers*(iterations/20)
+
+	if got := c.Len(); got != want {
+		t.Errorf("expected %d, got %d", want, got)
+	}
+
+	if _, xkcd := c.Get(1); xkcd == nil || xkcd.Title != "Comic 1" || xkcd.News != "updated" {
+		t.Errorf("expected only Update to change the stored comic, got %+v", xkcd)
+	}
+
+	all := c.GetAll()
+
+	for i := 1; i < len(all); i++ {
+		if all[i-1].Number >= all[i].Number {
+			t.Fatalf("expected ordered comics, got %d before %d", all[i-1].Number, all[i].Number)
+		}
+	}
+}
+
+func TestGetAllSnapshot(t *testing.T) {
+	c := Comics{}
+	c.Load(setupComics(3, false))
+
+	all := c.GetAll()
+	c.Add(&XKCD{Number: 4})
+	all[0].Title = "changed"
+
+	if len(all) != 3 {
+		t.Errorf("expected the snapshot to keep 3 comics, got %d", len(all))
+	}
+
+	if _, xkcd := c.Get(1); xkcd.Title == "changed" {
+		t.Errorf("expected the collection not to change through the snapshot")
+	}
+}
+
+func TestComicsGetReturnsCopy(t *testing.T) {
+	c := Comics{}
+	c.Load(setupComics(3, false))
+
+	_, xkcd := c.Get(2)
+	xkcd.Title = "changed"
+
+	if _, got := c.Get(2); got.Title != "Comic 2" {
+		t.Errorf("expected the stored comic not to change, got %s", got.Title)
+	}
+}
+
+func TestComicsUpdate(t *testing.T) {
+	c := Comics{}
+	c.Load(setupComics(3, false))
+
+	ok := c.Update(2, func(xkcd *XKCD) {
+		xkcd.Title = "changed"
+		xkcd.Number = 20
+	})
+
+	if !ok {
+		t.Fatalf("expected true, got %t", ok)
+	}
+
+	if _, got := c.Get(2); got == nil || got.Title != "changed" {
+		t.Errorf("expected the stored comic to change, got %v", got)
+	}
+
+	if c.Update(4, func(*XKCD) {}) {
+		t.Errorf("expected false for a missing comic")
+	}
+}
+
+// The tests below pin down that Get always returns an independent copy, both for a collection loaded in
+// order and for one built from comics added out of order (previously the binary and the sequential search).
+func TestComicsGetCopyAfterLoad(t *testing.T) {
+	c := Comics{}
+	c.Load(setupComics(10, false))
+
+	_, first := c.Get(5)
+	first.Title = "changed"
+	_, second := c.Get(5)
+
+	if first == second || second.Title != "Comic 5" {
+		t.Errorf("expected an independent copy, got %q", second.Title)
+	}
+}
+
+func TestComicsGetCopyAfterUnorderedAdd(t *testing.T) {
+	c := Comics{}
+
+	for _, num := range []int{9, 3, 5} {
+		c.Add(&XKCD{Number: num, Title: fmt.Sprintf("Comic %d", num)})
+	}
+
+	_, first := c.Get(5)
+	first.Title = "changed"
+	_, second := c.Get(5)
+
+	if first == second || second.Title != "Comic 5" {
+		t.Errorf("expected an independent copy, got %q", second.Title)
+	}
+
+	c.Update(5, func(xkcd *XKCD) { xkcd.Title = "updated" })
+
+	if first.Title != "changed" {
+		t.Errorf("expected Update not to change a copy returned before, got %q", first.Title)
+	}
+
+	if _, got := c.Get(5); got.Title != "updated" {
+		t.Errorf("expected Update to change the stored comic, got %q", got.Title)
+	}
+}
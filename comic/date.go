@@ -0,0 +1,118 @@
+package comic
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// DateLayout is the layout used when printing or parsing a publication date.
+const DateLayout = "2006-01-02"
+
+// Date returns the publication date of the comic parsed from the Year, Month and Day fields.
+// The date is in UTC. An error is returned if any of the fields is not a number or if together
+// they do not form a valid calendar date (e.g. 2021-02-30).
+func (xkcd *XKCD) Date() (time.Time, error) {
+	year, err := strconv.Atoi(xkcd.Year)
+
+	if err != nil {
+		return time.Time{}, fmt.Errorf("date %d: invalid year %q", xkcd.Number, xkcd.Year)
+	}
+
+	month, err := strconv.Atoi(xkcd.Month)
+
+	if err != nil || month < 1 || month > 12 {
+		return time.Time{}, fmt.Errorf("date %d: invalid month %q", xkcd.Number, xkcd.Month)
+	}
+
+	day, err := strconv.Atoi(xkcd.Day)
+
+	if err != nil {
+		return time.Time{}, fmt.Errorf("date %d: invalid day %q", xkcd.Number, xkcd.Day)
+	}
+
+	result := time.Date(year, time.Month(month), day, 0, 0, 0, 0, time.UTC)
+
+	// time.Date normalizes the values outside of their range, e.g. February 30 becomes March 2.
+	if result.Day() != day || int(result.Month()) != month {
+		return time.Time{}, fmt.Errorf("date %d: invalid day %q for %s-%s", xkcd.Number, xkcd.Day, xkcd.Year, xkcd.Month)
+	}
+
+	return result, nil
+}
+
+// FilterByDate returns the comics whose publication date satisfies match, ordered by the comic number.
+// The comics with a malformed date are skipped, see InvalidDates. match is called on a snapshot without
+// holding the lock, so it may use the collection.
+func (c *Comics) FilterByDate(match func(date time.Time) bool) []XKCD {
+	return c.Query().Filter(DateFilter(match)).All()
+}
+
+// DateFilter converts a condition on the publication date into a Query filter. The comics with
+// a malformed date never match.
+func DateFilter(match func(date time.Time) bool) func(xkcd *XKCD) bool {
+	return func(xkcd *XKCD) bool {
+		date, err := xkcd.Date()
+
+		return err == nil && match(date)
+	}
+}
+
+// InvalidDates returns the parse error for every comic whose publication date is malformed.
+func (c *Comics) InvalidDates() map[int]error {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	result := make(map[int]error)
+
+	for num, xkcd := range c.comics {
+		if _, err := xkcd.Date(); err != nil {
+			result[num] = err
+		}
+	}
+
+	return result
+}
+
+// ByYear returns the comics published in year.
+func (c *Comics) ByYear(year int) []XKCD {
+	return c.FilterByDate(func(date time.Time) bool {
+		return date.Year() == year
+	})
+}
+
+// ByMonth returns the comics published in the given month of year.
+func (c *Comics) ByMonth(year int, month time.Month) []XKCD {
+	return c.FilterByDate(func(date time.Time) bool {
+		return date.Year() == year && date.Month() == month
+	})
+}
+
+// ByWeekday returns the comics published on weekday.
+func (c *Comics) ByWeekday(weekday time.Weekday) []XKCD {
+	return c.FilterByDate(func(date time.Time) bool {
+		return date.Weekday() == weekday
+	})
+}
+
+// Between returns the comics published between from and to, both dates included.
+func (c *Comics) Between(from, to time.Time) []XKCD {
+	from = truncateDay(from)
+	to = truncateDay(to)
+
+	return c.FilterByDate(func(date time.Time) bool {
+		return !date.Before(from) && !date.After(to)
+	})
+}
+
+// OnThisDay returns the comics published on the given month and day in any year.
+func (c *Comics) OnThisDay(month time.Month, day int) []XKCD {
+	return c.FilterByDate(func(date time.Time) bool {
+		return date.Month() == month && date.Day() == day
+	})
+}
+
+// truncateDay returns the midnight UTC of the calendar day of t, so it can be compared to a publication date.
+func truncateDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,118 @@
+package comic
+
+import (
+	"testing"
+	"time"
+)
+
+func setupDatedComics() *Comics {
+	c := &Comics{}
+	c.Load([]XKCD{
+		{Number: 1, Year: "2006", Month: "1", Day: "1"},
+		{Number: 2, Year: "2006", Month: "3", Day: "15"},
+		{Number: 3, Year: "2007", Month: "3", Day: "15"},
+		{Number: 4, Year: "2007", Month: "12", Day: "31"},
+		{Number: 5, Year: "2007", Month: "2", Day: "30"},
+	})
+
+	return c
+}
+
+func TestDate(t *testing.T) {
+	xkcd := &XKCD{Number: 1, Year: "2022", Month: "1", Day: "9"}
+
+	got, err := xkcd.Date()
+
+	if err != nil {
+		t.Fatalf("expected err to be nil, got %v", err)
+	}
+
+	want := time.Date(2022, time.January, 9, 0, 0, 0, 0, time.UTC)
+
+	if !got.Equal(want) {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+}
+
+func TestDateInvalid(t *testing.T) {
+	tests := []XKCD{
+		{Number: 1, Year: "", Month: "1", Day: "1"},
+		{Number: 2, Year: "2022", Month: "13", Day: "1"},
+		{Number: 3, Year: "2022", Month: "1", Day: "x"},
+		{Number: 4, Year: "2022", Month: "2", Day: "30"},
+		{Number: 5, Year: "2022", Month: "2", Day: "0"},
+	}
+
+	for _, xkcd := range tests {
+		if _, err := xkcd.Date(); err == nil {
+			t.Errorf("expected error for %s-%s-%s, got nil", xkcd.Year, xkcd.Month, xkcd.Day)
+		}
+	}
+}
+
+func TestByYear(t *testing.T) {
+	got := setupDatedComics().ByYear(2007)
+
+	if len(got) != 2 || got[0].Number != 3 || got[1].Number != 4 {
+		t.Errorf("expected comics 3 and 4, got %v", got)
+	}
+}
+
+func TestByMonth(t *testing.T) {
+	got := setupDatedComics().ByMonth(2006, time.March)
+
+	if len(got) != 1 || got[0].Number != 2 {
+		t.Errorf("expected comic 2, got %v", got)
+	}
+}
+
+func TestByWeekday(t *testing.T) {
+	// 2006-01-01 was a Sunday
+	got := setupDatedComics().ByWeekday(time.Sunday)
+
+	if len(got) != 1 || got[0].Number != 1 {
+		t.Errorf("expected comic 1, got %v", got)
+	}
+}
+
+func TestBetween(t *testing.T) {
+	from := time.Date(2006, time.March, 15, 12, 0, 0, 0, time.UTC)
+	to := time.Date(2007, time.March, 15, 0, 0, 0, 0, time.UTC)
+
+	got := setupDatedComics().Between(from, to)
+
+	if len(got) != 2 || got[0].Number != 2 || got[1].Number != 3 {
+		t.Errorf("expected comics 2 and 3, got %v", got)
+	}
+}
+
+func TestOnThisDay(t *testing.T) {
+	got := setupDatedComics().OnThisDay(time.March, 15)
+
+	if len(got) != 2 {
+		t.Errorf("expected 2 comics, got %d", len(got))
+	}
+}
+
+func TestInvalidDates(t *testing.T) {
+	got := setupDatedComics().InvalidDates()
+
+	if len(got) != 1 || got[5] == nil {
+		t.Errorf("expected comic 5 to have an invalid date, got %v", got)
+	}
+}
+
+func TestFilterByDateUsesCollection(t *testing.T) {
+	c := setupDatedComics()
+
+	got := c.FilterByDate(func(date time.Time) bool {
+		// would deadlock if match was called while holding the lock
+		c.Add(&XKCD{Number: 100 + date.Day()})
+
+		return c.Contains(100 + date.Day())
+	})
+
+	if len(got) != 4 {
+		t.Errorf("expected 4 comics, got %d", len(got))
+	}
+}
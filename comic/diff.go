@@ -0,0 +1,54 @@
+package comic
+
+// ComicDiff holds the field changes of a comic present in both compared archives.
+type ComicDiff struct {
+	Number  int           `json:"num"`
+	Changes []FieldChange `json:"changes"`
+}
+
+// ArchiveDiff is the difference between two lists of comics, the left and the right one. The field
+// changes go from the left to the right record. All the lists are ordered by the comic number.
+type ArchiveDiff struct {
+	OnlyLeft  []int       `json:"only_left"`
+	OnlyRight []int       `json:"only_right"`
+	Changed   []ComicDiff `json:"changed"`
+
+	// comics that could not be read for the right archive, e.g. failed downloads, and are not compared
+	Unavailable []int `json:"unavailable,omitempty"`
+}
+
+// CompareArchives returns the comics present only in left or only in right and the field changes, see
+// Diff, of the comics present in both. Duplicates in either list are merged first, see Dedup.
+func CompareArchives(left, right []XKCD) ArchiveDiff {
+	left, _ = Dedup(left)
+	right, _ = Dedup(right)
+
+	result := ArchiveDiff{OnlyLeft: []int{}, OnlyRight: []int{}, Changed: []ComicDiff{}}
+	i, j := 0, 0
+
+	// both lists are ordered by number, so they are compared in a single pass
+	for i < len(left) || j < len(right) {
+		switch {
+		case j == len(right) || (i < len(left) && left[i].Number < right[j].Number):
+			result.OnlyLeft = append(result.OnlyLeft, left[i].Number)
+			i++
+		case i == len(left) || right[j].Number < left[i].Number:
+			result.OnlyRight = append(result.OnlyRight, right[j].Number)
+			j++
+		default:
+			if changes := Diff(&left[i], &right[j]); len(changes) > 0 {
+				result.Changed = append(result.Changed, ComicDiff{left[i].Number, changes})
+			}
+
+			i++
+			j++
+		}
+	}
+
+	return result
+}
+
+// Empty returns true if the compared archives hold the same comics.
+func (d ArchiveDiff) Empty() bool {
+	return len(d.OnlyLeft) == 0 && len(d.OnlyRight) == 0 && len(d.Changed) == 0 && len(d.Unavailable) == 0
+}
@@ -0,0 +1,34 @@
+package comic
+
+import "testing"
+
+func TestCompareArchives(t *testing.T) {
+	left := []XKCD{{Number: 1, Title: "One"}, {Number: 2, Title: "Two"}, {Number: 4, Title: "Four"}}
+	right := []XKCD{{Number: 4, Title: "Four"}, {Number: 3, Title: "Three"}, {Number: 2, Title: "Two!"}}
+
+	got := CompareArchives(left, right)
+
+	if len(got.OnlyLeft) != 1 || got.OnlyLeft[0] != 1 {
+		t.Errorf("expected [1] only on the left, got %v", got.OnlyLeft)
+	}
+
+	if len(got.OnlyRight) != 1 || got.OnlyRight[0] != 3 {
+		t.Errorf("expected [3] only on the right, got %v", got.OnlyRight)
+	}
+
+	if len(got.Changed) != 1 || got.Changed[0].Number != 2 || got.Changed[0].Changes[0].New != "Two!" {
+		t.Errorf("expected comic 2 to change, got %v", got.Changed)
+	}
+
+	if got.Empty() {
+		t.Errorf("expected a difference")
+	}
+}
+
+func TestCompareArchivesEqual(t *testing.T) {
+	items := []XKCD{{Number: 1, Title: "One"}}
+
+	if got := CompareArchives(items, items); !got.Empty() {
+		t.Errorf("expected no difference, got %+v", got)
+	}
+}
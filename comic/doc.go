@@ -18,6 +18,8 @@ DownloadImage method uses the image URL from the img element in the JSON file (o
 the image and convert it into a Base-64 encoded string. This string is stored in XKCD.Image. Some comics are
 returned with an img but the image cannot be retrieved. It requires parsing the HTML page to find the exact
 URL of the image. For that reason DownloadImage will always return a nil value for error.
+The image is decoded before it is stored, so a truncated download is discarded, and its size and SHA-256
+checksum are recorded in XKCD.ImageSize and XKCD.ImageHash. VerifyImage checks a stored image against them.
 
 Types and Values
 
@@ -42,17 +44,49 @@ from the xkcd website.
 
 The package also defines a collection with which to work.
 
-    type Comics []XKCD
+    type Comics struct {
+        // contains filtered or unexported fields
+    }
 
 The type Comics has a number of methods that allows it to manipulate the collection of XKCD values.
+The collection maps every comic number to its record, so Get and Contains take constant time regardless
+of the order in which the comics were added. The comic numbers are also kept in a sorted slice, into which
+a comic added out of order is inserted, so GetAll and the queries return the comics ordered by number
+without sorting them.
 
 Working with Comics collection
 
-The Comics collection can load, retrieve, remove and sort itself. When working with the collection
+The Comics collection can load, retrieve and remove comics. When working with the collection
 for the first time, you can either use Load(comics []XKCD) or Add(xkcd *XKCD) methods. The first method
 is used when you have comics stored in the file and you want to load them into a collection. For more
 information on loading see package persistence. The other method is used when you need to add one by one
 comic to the collection, as in the case when a new comic is added after XKCD.Download and XKCD.DownloadImage.
+A comic number is stored only once: adding a comic that is already in the collection merges the two records
+(see Merge), keeping the fields of the new record and the fields only the old record has, such as the Image.
+A comic downloaded again from the web site replaces the stored record with Refresh instead, which keeps only
+the local fields, such as the Image, so the fields cleared on the web site are cleared too.
+Dedup does the same for a list of comics and reports the duplicates it found.
+
+Ownership of the comics
+
+The collection owns the comics stored in it and never returns pointers to them. Get returns a copy of a comic
+and GetAll, as well as the query results, return copies of the comics, so the callers can change them freely.
+To change a stored comic use Update, which runs the change while holding the lock, or Add the changed comic,
+which merges it with the stored one.
+
+Querying the collection
+
+Comics.Query returns a Query over a snapshot of the collection, so the results never expose the stored comics.
+A query can be narrowed with Range and Filter, ordered with OrderBy (by number, date or title) and read with All,
+Each, Count or Page. Page returns the results in pages together with a cursor of the next page. NewQuery creates
+the same query over any list of comics. The CLI, the server and the exporters use queries to select comics.
+
+Publication dates
+
+The xkcd JSON stores the publication date as three strings. XKCD.Date parses them into a time.Time and reports
+an error if the values do not form a valid date. The collection can be queried by date using ByYear, ByMonth,
+ByWeekday, Between and OnThisDay, or with any other condition using FilterByDate. Comics with a malformed date
+are never returned by these queries and can be listed with InvalidDates.
 
 
 */
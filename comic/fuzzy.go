@@ -0,0 +1,351 @@
+package comic
+
+import (
+	"container/heap"
+	"strings"
+	"unicode"
+)
+
+// Weights of a matched word by the field it was found in.
+const (
+	fuzzyTitleWeight = 2
+	fuzzyAltWeight   = 1
+)
+
+// FuzzyMatch is a comic found by a fuzzy search with its score, from 0 to 1.
+type FuzzyMatch struct {
+	Number int
+	Title  string
+	Score  float64
+}
+
+// FuzzyIndex finds comics by words of the title and the alt text that are misspelled or half-remembered.
+// The words of the comics are indexed by their trigrams, so only the words sharing a trigram with a
+// searched word are compared by edit distance.
+type FuzzyIndex struct {
+	words    []string         // vocabulary
+	ids      map[string]int   // word → position in words
+	trigrams map[string][]int // trigram → words containing it
+	postings [][]fuzzyPosting // word → comics containing it
+	titles   map[int]string
+}
+
+// fuzzyPosting is an occurrence of a word in a comic, with the weight of the field.
+type fuzzyPosting struct {
+	number int
+	weight int
+}
+
+// NewFuzzyIndex indexes the titles and alt texts of the comics in the collection. The index does not follow
+// later changes of the collection.
+func NewFuzzyIndex(c *Comics) *FuzzyIndex {
+	idx := &FuzzyIndex{ids: map[string]int{}, trigrams: map[string][]int{}, titles: map[int]string{}}
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	for _, num := range c.numbers {
+		xkcd := c.comics[num]
+		idx.titles[num] = xkcd.Title
+		seen := map[int]int{}
+
+		for _, field := range []struct {
+			text   string
+			weight int
+		}{{xkcd.Title, fuzzyTitleWeight}, {xkcd.ImageAlt, fuzzyAltWeight}} {
+			for _, word := range fuzzyWords(field.text) {
+				id := idx.add(word)
+
+				if seen[id] < field.weight {
+					seen[id] = field.weight
+				}
+			}
+		}
+
+		for id, weight := range seen {
+			idx.postings[id] = append(idx.postings[id], fuzzyPosting{num, weight})
+		}
+	}
+
+	return idx
+}
+
+// add adds word to the vocabulary and returns its id.
+func (idx *FuzzyIndex) add(word string) int {
+	if id, ok := idx.ids[word]; ok {
+		return id
+	}
+
+	id := len(idx.words)
+	idx.ids[word] = id
+	idx.words = append(idx.words, word)
+	idx.postings = append(idx.postings, nil)
+
+	for _, trigram := range trigrams(word) {
+		idx.trigrams[trigram] = append(idx.trigrams[trigram], id)
+	}
+
+	return id
+}
+
+// Search returns up to limit comics ranked by how well their titles and alt texts match the words of
+// the query, allowing a few typos per word. Words of the title count twice as much as the alt text.
+// A limit of 0 or less returns all the matches.
+func (idx *FuzzyIndex) Search(query string, limit int) []FuzzyMatch {
+	words := fuzzyWords(query)
+
+	if len(words) == 0 {
+		return nil
+	}
+
+	// best similarity times the field weight for every matched comic and query word, in rows of
+	// len(words) scores
+	rows := map[int]int{}
+	var scores []float64
+
+	for i, word := range words {
+		for id, similarity := range idx.similar(word) {
+			for _, posting := range idx.postings[id] {
+				row, ok := rows[posting.number]
+
+				if !ok {
+					row = len(scores)
+					rows[posting.number] = row
+					scores = append(scores, make([]float64, len(words))...)
+				}
+
+				if score := similarity * float64(posting.weight); score > scores[row+i] {
+					scores[row+i] = score
+				}
+			}
+		}
+	}
+
+	// with a limit only the best matches are kept, in a heap with the worst of them on top
+	results := &fuzzyMatches{}
+
+	for num, row := range rows {
+		total := 0.0
+
+		for _, score := range scores[row : row+len(words)] {
+			total += score
+		}
+
+		match := FuzzyMatch{num, idx.titles[num], total / float64(fuzzyTitleWeight*len(words))}
+
+		switch {
+		case limit <= 0 || results.Len() < limit:
+			heap.Push(results, match)
+		case results.better(match, (*results)[0]):
+			(*results)[0] = match
+			heap.Fix(results, 0)
+		}
+	}
+
+	sorted := make([]FuzzyMatch, results.Len())
+
+	for i := len(sorted) - 1; i >= 0; i-- {
+		sorted[i] = heap.Pop(results).(FuzzyMatch)
+	}
+
+	return sorted
+}
+
+// fuzzyMatches is a heap of matches with the worst match first.
+type fuzzyMatches []FuzzyMatch
+
+// better ranks a before b by the score, then by the comic number.
+func (m fuzzyMatches) better(a, b FuzzyMatch) bool {
+	if a.Score != b.Score {
+		return a.Score > b.Score
+	}
+
+	return a.Number < b.Number
+}
+
+func (m fuzzyMatches) Len() int            { return len(m) }
+func (m fuzzyMatches) Less(i, j int) bool  { return m.better(m[j], m[i]) }
+func (m fuzzyMatches) Swap(i, j int)       { m[i], m[j] = m[j], m[i] }
+func (m *fuzzyMatches) Push(x interface{}) { *m = append(*m, x.(FuzzyMatch)) }
+
+func (m *fuzzyMatches) Pop() interface{} {
+	old := *m
+	x := old[len(old)-1]
+	*m = old[:len(old)-1]
+
+	return x
+}
+
+// DidYouMean returns the query with every unknown word replaced by the closest word of the titles and alt
+// texts, preferring the more frequent words. Only the text terms of the query are corrected, see
+// RewriteSearch, so its fields and operators are kept. It returns "" if there is nothing to correct.
+func (idx *FuzzyIndex) DidYouMean(query string) string {
+	changed := false
+
+	result, err := RewriteSearch(query, func(text string) string {
+		if corrected, ok := idx.correct(text); ok {
+			changed = true
+			return corrected
+		}
+
+		return text
+	})
+
+	if err != nil || !changed {
+		return ""
+	}
+
+	return result
+}
+
+// correct returns the words of text with the unknown words replaced by the closest known words, and false
+// if no word was replaced.
+func (idx *FuzzyIndex) correct(text string) (string, bool) {
+	words := fuzzyWords(text)
+	changed := false
+
+	for i, word := range words {
+		if _, ok := idx.ids[word]; ok {
+			continue
+		}
+
+		best, bestSimilarity := -1, 0.0
+
+		for id, similarity := range idx.similar(word) {
+			if best < 0 || similarity > bestSimilarity ||
+				similarity == bestSimilarity && (len(idx.postings[id]) > len(idx.postings[best]) ||
+					len(idx.postings[id]) == len(idx.postings[best]) && idx.words[id] < idx.words[best]) {
+				best, bestSimilarity = id, similarity
+			}
+		}
+
+		if best >= 0 {
+			words[i] = idx.words[best]
+			changed = true
+		}
+	}
+
+	return strings.Join(words, " "), changed
+}
+
+// similar returns the words of the vocabulary within the allowed edit distance of word, with their
+// similarity from 0 to 1. An edit changes at most three trigrams, so only the words sharing enough
+// trigrams with word are compared.
+func (idx *FuzzyIndex) similar(word string) map[int]float64 {
+	maxDistance := allowedDistance(word)
+	grams := trigrams(word)
+	shared := map[int]int{}
+
+	for _, trigram := range grams {
+		for _, id := range idx.trigrams[trigram] {
+			shared[id]++
+		}
+	}
+
+	result := map[int]float64{}
+	length := len([]rune(word))
+
+	for id, count := range shared {
+		if count < len(grams)-3*maxDistance {
+			continue
+		}
+
+		candidate := idx.words[id]
+		candidateLength := len([]rune(candidate))
+
+		if abs(candidateLength-length) > maxDistance {
+			continue
+		}
+
+		if distance := Levenshtein(word, candidate); distance <= maxDistance {
+			longer := length
+
+			if candidateLength > longer {
+				longer = candidateLength
+			}
+
+			result[id] = 1 - float64(distance)/float64(longer)
+		}
+	}
+
+	return result
+}
+
+// allowedDistance is the number of typos tolerated in a word, growing with its length.
+func allowedDistance(word string) int {
+	switch n := len([]rune(word)); {
+	case n <= 2:
+		return 0
+	case n <= 5:
+		return 1
+	case n <= 8:
+		return 2
+	}
+
+	return 3
+}
+
+// Levenshtein returns the edit distance between a and b: the number of runes inserted, deleted or
+// substituted to change one into the other.
+func Levenshtein(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+
+			curr[j] = prev[j-1] + cost
+
+			if prev[j]+1 < curr[j] {
+				curr[j] = prev[j] + 1
+			}
+
+			if curr[j-1]+1 < curr[j] {
+				curr[j] = curr[j-1] + 1
+			}
+		}
+
+		prev, curr = curr, prev
+	}
+
+	return prev[len(rb)]
+}
+
+// trigrams returns the trigrams of word padded with spaces, so short words and word boundaries have trigrams too.
+func trigrams(word string) []string {
+	runes := []rune("  " + word + " ")
+	result := make([]string, 0, len(runes)-2)
+
+	for i := 0; i+3 <= len(runes); i++ {
+		result = append(result, string(runes[i:i+3]))
+	}
+
+	return result
+}
+
+// fuzzyWords splits text into lower case words of letters and digits.
+func fuzzyWords(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+
+	return n
+}
@@ -0,0 +1,18 @@
+package comic
+
+import "testing"
+
+func BenchmarkFuzzyIndex(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		NewFuzzyIndex(c2k)
+	}
+}
+
+func BenchmarkFuzzySearch(b *testing.B) {
+	idx := NewFuzzyIndex(c2k)
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		idx.Search("comc 1234", 10)
+	}
+}
@@ -0,0 +1,92 @@
+package comic
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestLevenshtein(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"", "", 0},
+		{"abc", "", 3},
+		{"kitten", "sitting", 3},
+		{"algebra", "algerba", 2},
+		{"café", "cafe", 1},
+		{"flaw", "lawn", 2},
+	}
+
+	for _, test := range tests {
+		if got := Levenshtein(test.a, test.b); got != test.want {
+			t.Errorf("%q, %q: expected %d, got %d", test.a, test.b, test.want, got)
+		}
+
+		if got := Levenshtein(test.b, test.a); got != test.want {
+			t.Errorf("%q, %q: expected %d, got %d", test.b, test.a, test.want, got)
+		}
+	}
+}
+
+func fuzzyComics() *Comics {
+	c := &Comics{}
+	c.Load([]XKCD{
+		{Number: 1, Title: "Barrel - Part 1", ImageAlt: "Don't we all."},
+		{Number: 149, Title: "Sandwich", ImageAlt: "Proper User Policy apparently means Simon Says."},
+		{Number: 303, Title: "Compiling", ImageAlt: "'Are you stealing those LCDs?' 'Yeah, but I'm doing it while my code compiles.'"},
+		{Number: 327, Title: "Exploits of a Mom", ImageAlt: "Her daughter is named Help I'm trapped in a driver's license factory."},
+		{Number: 353, Title: "Python", ImageAlt: "I wrote 20 short programs in Python yesterday. It was wonderful."},
+		{Number: 1050, Title: "Forgot Algebra", ImageAlt: "Compiling the algebra was easier."},
+	})
+
+	return c
+}
+
+func TestFuzzySearch(t *testing.T) {
+	idx := NewFuzzyIndex(fuzzyComics())
+
+	tests := map[string][]int{
+		"sandwhich":         {149},
+		"Exploits of a mum": {327},
+		"pyton":             {353},
+		"compling":          {303, 1050},
+		"algebra compiled":  {1050, 303},
+		"zzzz":              nil,
+	}
+
+	for query, want := range tests {
+		var got []int
+
+		for _, match := range idx.Search(query, 0) {
+			got = append(got, match.Number)
+		}
+
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("%q: expected %v, got %v", query, want, got)
+		}
+	}
+
+	if matches := idx.Search("pyton", 1); len(matches) != 1 || matches[0].Title != "Python" || matches[0].Score <= 0 || matches[0].Score >= 1 {
+		t.Errorf("unexpected match %+v", matches)
+	}
+}
+
+func TestDidYouMean(t *testing.T) {
+	idx := NewFuzzyIndex(fuzzyComics())
+
+	tests := map[string]string{
+		"sandwhich":       "sandwich",
+		"Forgott algebra": "forgot algebra",
+		"python":          "",
+		`title:"forgott algebra" year:2012 -alt:compling`: `title:"forgot algebra" year:2012 -alt:compiling`,
+		`title:python OR pyton`:                           `title:python OR python`,
+		"zzzz":                                            "",
+	}
+
+	for query, want := range tests {
+		if got := idx.DidYouMean(query); got != want {
+			t.Errorf("%q: expected %q, got %q", query, want, got)
+		}
+	}
+}
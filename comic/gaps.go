@@ -0,0 +1,32 @@
+package comic
+
+import "xkcd2/config"
+
+// MissingNumbers returns the comic numbers between 1 and last (inclusive) that are not in comics,
+// excluding config.AbsentComics. If last is 0 or less, the highest number in comics is used.
+func MissingNumbers(comics []XKCD, last int) []int {
+	present := make(map[int]bool, len(comics))
+
+	for _, xkcd := range comics {
+		present[xkcd.Number] = true
+
+		if xkcd.Number > last {
+			last = xkcd.Number
+		}
+	}
+
+	result := []int{}
+
+	for num := 1; num <= last; num++ {
+		if !present[num] && !config.IsAbsent(num) {
+			result = append(result, num)
+		}
+	}
+
+	return result
+}
+
+// Missing returns the gaps in the collection between 1 and last, see MissingNumbers.
+func (c *Comics) Missing(last int) []int {
+	return MissingNumbers(c.snapshot(), last)
+}
@@ -0,0 +1,30 @@
+package comic
+
+import "testing"
+
+func TestMissingNumbers(t *testing.T) {
+	comics := []XKCD{{Number: 2}, {Number: 5}, {Number: 403}, {Number: 405}}
+
+	got := MissingNumbers(comics, 0)
+
+	if len(got) != 400 || got[0] != 1 || got[1] != 3 || got[2] != 4 || got[3] != 6 {
+		t.Fatalf("expected 400 missing comics starting with 1, 3, 4, 6, got %d", len(got))
+	}
+
+	for _, num := range got {
+		if num == 404 {
+			t.Errorf("expected absent comic 404 not to be reported")
+		}
+	}
+}
+
+func TestMissingUpToLast(t *testing.T) {
+	c := Comics{}
+	c.Load([]XKCD{{Number: 1}, {Number: 2}})
+
+	got := c.Missing(4)
+
+	if len(got) != 2 || got[0] != 3 || got[1] != 4 {
+		t.Errorf("expected [3 4], got %v", got)
+	}
+}
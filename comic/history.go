@@ -0,0 +1,99 @@
+package comic
+
+import (
+	"reflect"
+	"time"
+)
+
+// TrackedFields are the upstream fields of XKCD compared by Diff and recorded in the revision history.
+var TrackedFields = []string{"Title", "SafeTitle", "ImageAlt", "Transcript", "ImageURL", "News", "Link", "Year", "Month", "Day"}
+
+// FieldChange is a change of a single field of a comic.
+type FieldChange struct {
+	Field string `json:"field"`
+	Old   string `json:"old"`
+	New   string `json:"new"`
+}
+
+// Revision is a set of changes to a comic observed at the same time, e.g. when a comic corrected
+// on the xkcd web site was downloaded again.
+type Revision struct {
+	Observed time.Time
+	Changes  []FieldChange
+}
+
+// now returns the time a revision is observed at. Tests replace it to get a fixed time.
+var now = time.Now
+
+// Diff returns the changes of TrackedFields between the old and the new record of a comic.
+func Diff(old, new *XKCD) []FieldChange {
+	var result []FieldChange
+
+	from := reflect.ValueOf(old).Elem()
+	to := reflect.ValueOf(new).Elem()
+
+	for _, field := range TrackedFields {
+		oldValue := from.FieldByName(field).String()
+		newValue := to.FieldByName(field).String()
+
+		if oldValue != newValue {
+			result = append(result, FieldChange{field, oldValue, newValue})
+		}
+	}
+
+	return result
+}
+
+// History returns the revisions of comicNum, the oldest first, or nil if the comic never changed.
+func (c *Comics) History(comicNum int) []Revision {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return append([]Revision(nil), c.history[comicNum]...)
+}
+
+// Histories returns a copy of the revision history of all the comics, e.g. for writing it to a file.
+func (c *Comics) Histories() map[int][]Revision {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	result := make(map[int][]Revision, len(c.history))
+
+	for num, revisions := range c.history {
+		result[num] = append([]Revision(nil), revisions...)
+	}
+
+	return result
+}
+
+// LoadHistory replaces the revision history with history, e.g. read from a file.
+func (c *Comics) LoadHistory(history map[int][]Revision) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.history = make(map[int][]Revision, len(history))
+
+	for num, revisions := range history {
+		c.history[num] = append([]Revision(nil), revisions...)
+	}
+}
+
+// recordRevision adds the changes between old and new to the history of the comic.
+// The caller must hold the write lock.
+func (c *Comics) recordRevision(old, new *XKCD) {
+	c.recordChanges(old.Number, Diff(old, new))
+}
+
+// recordChanges adds a revision with changes to the history of comicNum, unless there are no changes.
+// The caller must hold the write lock.
+func (c *Comics) recordChanges(comicNum int, changes []FieldChange) {
+	if len(changes) == 0 {
+		return
+	}
+
+	if c.history == nil {
+		c.history = make(map[int][]Revision)
+	}
+
+	c.history[comicNum] = append(c.history[comicNum], Revision{now(), changes})
+}
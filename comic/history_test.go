@@ -0,0 +1,48 @@
+package comic
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDiff(t *testing.T) {
+	old := &XKCD{Number: 1, Title: "Title", ImageAlt: "alt", Image: "aW1hZ2U="}
+	new := &XKCD{Number: 1, Title: "Corrected title", ImageAlt: "alt"}
+
+	got := Diff(old, new)
+
+	if len(got) != 1 || got[0] != (FieldChange{"Title", "Title", "Corrected title"}) {
+		t.Errorf("expected only the title change, got %v", got)
+	}
+}
+
+func TestComicsHistory(t *testing.T) {
+	saved := now
+	defer func() { now = saved }()
+
+	observed := time.Date(2022, time.March, 1, 10, 0, 0, 0, time.UTC)
+	now = func() time.Time { return observed }
+
+	c := Comics{}
+	c.Add(&XKCD{Number: 1, Title: "Title", Transcript: "old"})
+	c.Add(&XKCD{Number: 1, Title: "Title", Transcript: "old"})
+
+	if got := c.History(1); len(got) != 0 {
+		t.Fatalf("expected no revisions for an unchanged comic, got %v", got)
+	}
+
+	c.Add(&XKCD{Number: 1, Title: "New title", Transcript: "new"})
+
+	got := c.History(1)
+
+	if len(got) != 1 || !got[0].Observed.Equal(observed) || len(got[0].Changes) != 2 {
+		t.Fatalf("expected one revision with 2 changes, got %v", got)
+	}
+
+	c2 := Comics{}
+	c2.LoadHistory(c.Histories())
+
+	if len(c2.History(1)) != 1 {
+		t.Errorf("expected the loaded history to contain the revision")
+	}
+}
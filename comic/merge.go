@@ -0,0 +1,82 @@
+package comic
+
+import (
+	"reflect"
+	"sort"
+)
+
+// Duplicate reports a comic number that was found Count times in a list of comics.
+type Duplicate struct {
+	Number int
+	Count  int
+}
+
+// Merge returns the combination of two records of the same comic. The incoming record is considered
+// the newest, so its fields win, but the fields it lacks are kept from the existing record. For example,
+// re-downloading the JSON of a comic does not drop the Image downloaded before.
+func Merge(existing, incoming *XKCD) XKCD {
+	result := *incoming
+
+	from := reflect.ValueOf(existing).Elem()
+	to := reflect.ValueOf(&result).Elem()
+
+	for i := 0; i < to.NumField(); i++ {
+		if to.Field(i).IsZero() {
+			to.Field(i).Set(from.Field(i))
+		}
+	}
+
+	return result
+}
+
+// Refresh returns the record of a comic downloaded again from the web site, incoming, combined with the
+// local fields of the stored record, existing. Unlike Merge, the fields received from the web site, Original,
+// Payload and Validators always come from incoming, so a field cleared on the web site is cleared too.
+// The image and the data computed from it are kept only if the image URL did not change.
+func Refresh(existing, incoming *XKCD) XKCD {
+	result := incoming.clone()
+
+	if existing.ImageURL == incoming.ImageURL {
+		result.Image, result.ImageSize, result.ImageHash = existing.Image, existing.ImageSize, existing.ImageHash
+		result.Hashes, result.Metadata = existing.Hashes, existing.Metadata
+		result.Assets = append([]Asset(nil), existing.Assets...)
+	}
+
+	return result
+}
+
+// Dedup merges the comics with the same number and returns the unique comics ordered by number together
+// with the duplicates found. The items are merged in the order they appear, the later item being the newer.
+func Dedup(items []XKCD) ([]XKCD, []Duplicate) {
+	merged := make(map[int]*XKCD, len(items))
+	counts := make(map[int]int)
+
+	for i := range items {
+		num := items[i].Number
+		counts[num]++
+
+		if existing, ok := merged[num]; ok {
+			*existing = Merge(existing, &items[i])
+			continue
+		}
+
+		xkcd := items[i]
+		merged[num] = &xkcd
+	}
+
+	result := make([]XKCD, 0, len(merged))
+	var duplicates []Duplicate
+
+	for num, xkcd := range merged {
+		result = append(result, *xkcd)
+
+		if counts[num] > 1 {
+			duplicates = append(duplicates, Duplicate{num, counts[num]})
+		}
+	}
+
+	sort.Slice(result, func(i, j int) bool { return result[i].Number < result[j].Number })
+	sort.Slice(duplicates, func(i, j int) bool { return duplicates[i].Number < duplicates[j].Number })
+
+	return result, duplicates
+}
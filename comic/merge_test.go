@@ -0,0 +1,106 @@
+package comic
+
+import "testing"
+
+func TestMergeKeepsExistingImage(t *testing.T) {
+	existing := &XKCD{Number: 1, Title: "Old title", Image: "aW1hZ2U=", News: "news"}
+	incoming := &XKCD{Number: 1, Title: "New title"}
+
+	got := Merge(existing, incoming)
+
+	if got.Title != "New title" {
+		t.Errorf("expected the incoming title, got %s", got.Title)
+	}
+
+	if got.Image != existing.Image || got.News != "news" {
+		t.Errorf("expected the existing image and news to be kept, got %+v", got)
+	}
+}
+
+func TestDedup(t *testing.T) {
+	items := []XKCD{
+		{Number: 2, Title: "Two", Image: "aW1hZ2U="},
+		{Number: 1, Title: "One"},
+		{Number: 2, Title: "Two corrected"},
+		{Number: 2, Title: "Two corrected"},
+	}
+
+	got, duplicates := Dedup(items)
+
+	if len(got) != 2 || got[0].Number != 1 || got[1].Number != 2 {
+		t.Fatalf("expected comics 1 and 2, got %v", got)
+	}
+
+	if got[1].Title != "Two corrected" || got[1].Image == "" {
+		t.Errorf("expected the newest title with the stored image, got %+v", got[1])
+	}
+
+	if len(duplicates) != 1 || duplicates[0] != (Duplicate{2, 3}) {
+		t.Errorf("expected comic 2 stored 3 times, got %v", duplicates)
+	}
+}
+
+func TestComicsAddUpsert(t *testing.T) {
+	c := Comics{}
+	c.Add(&XKCD{Number: 1, Title: "One", Image: "aW1hZ2U="})
+	c.Add(&XKCD{Number: 1, Title: "One corrected"})
+
+	if c.Len() != 1 {
+		t.Errorf("expected 1, got %d", c.Len())
+	}
+
+	if _, got := c.Get(1); got.Title != "One corrected" || got.Image == "" {
+		t.Errorf("expected the merged comic, got %+v", got)
+	}
+}
+
+func TestComicsLoadDuplicates(t *testing.T) {
+	c := Comics{}
+	c.Load([]XKCD{{Number: 1, Image: "aW1hZ2U="}, {Number: 1, Title: "One"}})
+
+	if c.Len() != 1 {
+		t.Errorf("expected 1, got %d", c.Len())
+	}
+
+	if _, got := c.Get(1); got.Title != "One" || got.Image == "" {
+		t.Errorf("expected the merged comic, got %+v", got)
+	}
+}
+
+func TestRefreshClearsUpstreamFields(t *testing.T) {
+	existing := &XKCD{Number: 1, Title: "One", News: "news", ImageURL: "https://imgs.xkcd.com/one.png", Image: "aW1hZ2U=",
+		ImageHash: "abc", Original: map[string]string{"Title": "One&amp;"}, Payload: []byte(`{"num":1,"news":"news"}`)}
+	incoming := &XKCD{Number: 1, Title: "One", ImageURL: "https://imgs.xkcd.com/one.png", Payload: []byte(`{"num":1}`)}
+
+	got := Refresh(existing, incoming)
+
+	if got.News != "" || got.Original != nil || string(got.Payload) != `{"num":1}` {
+		t.Errorf("expected the upstream fields of the incoming record, got %+v", got)
+	}
+
+	if got.Image != existing.Image || got.ImageHash != "abc" {
+		t.Errorf("expected the stored image to be kept, got %+v", got)
+	}
+
+	incoming.ImageURL = "https://imgs.xkcd.com/one_2.png"
+
+	if got := Refresh(existing, incoming); got.Image != "" || got.ImageHash != "" {
+		t.Errorf("expected the image to be dropped when its URL changed, got %+v", got)
+	}
+}
+
+func TestComicsRefreshRecordsClearedField(t *testing.T) {
+	c := Comics{}
+	c.Load([]XKCD{{Number: 1, Title: "One", News: "news", Image: "aW1hZ2U="}})
+	c.Refresh(&XKCD{Number: 1, Title: "One"})
+
+	_, got := c.Get(1)
+
+	if got.News != "" || got.Image == "" {
+		t.Errorf("expected the news cleared and the image kept, got %+v", got)
+	}
+
+	if history := c.History(1); len(history) != 1 || history[0].Changes[0] != (FieldChange{"News", "news", ""}) {
+		t.Errorf("expected the cleared news in the history, got %+v", history)
+	}
+}
@@ -0,0 +1,24 @@
+package comic
+
+import "xkcd2/tools/imaging"
+
+// OversizedSize is the width or height in pixels from which an image is considered oversized.
+const OversizedSize = 1500
+
+// MetadataFilter converts a condition on the image metadata into a Query filter. The comics whose
+// image was not analyzed never match.
+func MetadataFilter(match func(metadata imaging.Metadata) bool) func(xkcd *XKCD) bool {
+	return func(xkcd *XKCD) bool {
+		return xkcd.Analyzed() && match(xkcd.Metadata)
+	}
+}
+
+// Analyzed returns true if the metadata of the image was recorded.
+func (xkcd *XKCD) Analyzed() bool {
+	return xkcd.Metadata.Format != ""
+}
+
+// Oversized returns true if the image is wider or taller than OversizedSize.
+func Oversized(metadata imaging.Metadata) bool {
+	return metadata.Width > OversizedSize || metadata.Height > OversizedSize
+}
@@ -0,0 +1,44 @@
+package comic
+
+import (
+	"testing"
+
+	"xkcd2/tools/imaging"
+)
+
+func TestMetadataFilter(t *testing.T) {
+	var c Comics
+
+	c.Load([]XKCD{
+		{Number: 1, Metadata: imaging.Metadata{Format: "png", Width: 600, Height: 400, Frames: 1}},
+		{Number: 2, Metadata: imaging.Metadata{Format: "gif", Width: 600, Height: 400, Frames: 12}},
+		{Number: 3, Metadata: imaging.Metadata{Format: "png", Width: 4000, Height: 400, Frames: 1, Colorfulness: 40}},
+		{Number: 4},
+	})
+
+	tests := []struct {
+		name  string
+		match func(metadata imaging.Metadata) bool
+		want  []int
+	}{
+		{"animated", imaging.Metadata.Animated, []int{2}},
+		{"color", imaging.Metadata.Color, []int{3}},
+		{"oversized", Oversized, []int{3}},
+		{"all", func(imaging.Metadata) bool { return true }, []int{1, 2, 3}},
+	}
+
+	for _, tt := range tests {
+		got := c.Query().Filter(MetadataFilter(tt.match)).All()
+
+		if len(got) != len(tt.want) {
+			t.Errorf("%s: expected %v, got %d comics", tt.name, tt.want, len(got))
+			continue
+		}
+
+		for i, num := range tt.want {
+			if got[i].Number != num {
+				t.Errorf("%s: expected %v, got comic %d at %d", tt.name, tt.want, got[i].Number, i)
+			}
+		}
+	}
+}
@@ -0,0 +1,152 @@
+package comic
+
+import (
+	"html"
+	"strings"
+	"unicode/utf8"
+)
+
+// NormalizedFields are the text fields of XKCD cleaned by Normalize.
+var NormalizedFields = []string{"Title", "SafeTitle", "ImageAlt", "Transcript"}
+
+// Normalize cleans the text fields of the comic as received from the web site: it unescapes HTML entities,
+// also when they are encoded twice, repairs UTF-8 text that was decoded as Windows-1252 (mojibake) and
+// replaces the Latin letters followed by a combining accent with the precomposed letters, see
+// composeLatinAccents. The original value of every changed field is kept in Original.
+func (xkcd *XKCD) Normalize() {
+	for _, field := range NormalizedFields {
+		value := xkcd.field(field)
+		cleaned := NormalizeText(*value)
+
+		if cleaned == *value {
+			continue
+		}
+
+		if xkcd.Original == nil {
+			xkcd.Original = make(map[string]string)
+		}
+
+		xkcd.Original[field] = *value
+		*value = cleaned
+	}
+}
+
+// NormalizeText returns the normalized text, see XKCD.Normalize.
+func NormalizeText(text string) string {
+	// "&amp;#39;" takes two passes, more would unescape text that contains entities on purpose
+	for i := 0; i < 2 && strings.Contains(text, "&"); i++ {
+		text = html.UnescapeString(text)
+	}
+
+	return composeLatinAccents(fixMojibake(text))
+}
+
+// UpstreamDiff returns the changes of TrackedFields from the stored record of a comic to the record downloaded
+// again from the web site, see Diff. The text fields of the stored record are normalized first, so the records
+// stored before they were normalized on download do not show spurious changes.
+func UpstreamDiff(stored, downloaded *XKCD) []FieldChange {
+	old := *stored
+
+	for _, field := range NormalizedFields {
+		value := old.field(field)
+		*value = NormalizeText(*value)
+	}
+
+	return Diff(&old, downloaded)
+}
+
+// field returns the pointer to one of NormalizedFields.
+func (xkcd *XKCD) field(name string) *string {
+	switch name {
+	case "Title":
+		return &xkcd.Title
+	case "SafeTitle":
+		return &xkcd.SafeTitle
+	case "ImageAlt":
+		return &xkcd.ImageAlt
+	default:
+		return &xkcd.Transcript
+	}
+}
+
+// windows1252 maps the characters of Windows-1252 in the range 0x80-0x9f to their bytes. The other
+// characters up to 0xff are the same as in Latin-1.
+var windows1252 = map[rune]byte{
+	'€': 0x80, '‚': 0x82, 'ƒ': 0x83, '„': 0x84, '…': 0x85, '†': 0x86, '‡': 0x87, 'ˆ': 0x88, '‰': 0x89,
+	'Š': 0x8a, '‹': 0x8b, 'Œ': 0x8c, 'Ž': 0x8e, '‘': 0x91, '’': 0x92, '“': 0x93, '”': 0x94, '•': 0x95,
+	'–': 0x96, '—': 0x97, '˜': 0x98, '™': 0x99, 'š': 0x9a, '›': 0x9b, 'œ': 0x9c, 'ž': 0x9e, 'Ÿ': 0x9f,
+}
+
+// fixMojibake repairs text whose UTF-8 bytes were decoded as Windows-1252, such as "cafÃ©" for "café".
+// The text is encoded back to Windows-1252 and kept only if the bytes are valid UTF-8 with fewer characters,
+// so text that merely contains accented letters is not changed.
+func fixMojibake(text string) string {
+	if !strings.ContainsAny(text, "ÃÂâÅ") {
+		return text
+	}
+
+	raw := make([]byte, 0, len(text))
+
+	for _, r := range text {
+		if b, ok := windows1252[r]; ok {
+			raw = append(raw, b)
+		} else if r < 0x100 {
+			raw = append(raw, byte(r))
+		} else {
+			return text
+		}
+	}
+
+	if !utf8.Valid(raw) || utf8.RuneCount(raw) >= utf8.RuneCountInString(text) {
+		return text
+	}
+
+	return string(raw)
+}
+
+// compositions maps a letter followed by a combining mark to the precomposed letter. It covers the accents
+// of the Latin languages, which is what the comics use, not the whole Unicode composition table.
+var compositions = map[[2]rune]rune{}
+
+func init() {
+	marks := map[rune]struct{ bases, composed string }{
+		'̀': {"AEIOUaeiou", "ÀÈÌÒÙàèìòù"},
+		'́': {"AEIOUYaeiouyCNSZcnsz", "ÁÉÍÓÚÝáéíóúýĆŃŚŹćńśź"},
+		'̂': {"AEIOUaeiou", "ÂÊÎÔÛâêîôû"},
+		'̃': {"ANOano", "ÃÑÕãñõ"},
+		'̈': {"AEIOUaeiouy", "ÄËÏÖÜäëïöüÿ"},
+		'̊': {"Aa", "Åå"},
+		'̧': {"Cc", "Çç"},
+		'̌': {"CSZcsz", "ČŠŽčšž"},
+	}
+
+	for mark, table := range marks {
+		composed := []rune(table.composed)
+
+		for i, base := range []rune(table.bases) {
+			compositions[[2]rune{base, mark}] = composed[i]
+		}
+	}
+}
+
+// composeLatinAccents replaces the letters followed by one of the combining accents in compositions with
+// the precomposed letters. This is not Unicode NFC: other scripts, letters with several marks and marks
+// that need reordering are left as they are.
+func composeLatinAccents(text string) string {
+	runes := []rune(text)
+	result := make([]rune, 0, len(runes))
+
+	for i := 0; i < len(runes); i++ {
+		if i+1 < len(runes) {
+			if composed, ok := compositions[[2]rune{runes[i], runes[i+1]}]; ok {
+				result = append(result, composed)
+				i++
+				continue
+			}
+		}
+
+		result = append(result, runes[i])
+	}
+
+	return string(result)
+}
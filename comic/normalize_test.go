@@ -0,0 +1,52 @@
+package comic
+
+import "testing"
+
+func TestNormalizeText(t *testing.T) {
+	tests := map[string]string{
+		"Tom &amp; Jerry":         "Tom & Jerry",
+		"It&amp;#39;s":            "It's",
+		"&lt;b&gt; &amp;amp;amp;": "<b> &amp;",
+		"cafÃ©":                   "café",
+		"Itâ€™s":                  "It’s",
+		"café and Ã la carte":     "café and Ã la carte",
+		"café":                   "café",
+		"Plain text, no changes.": "Plain text, no changes.",
+	}
+
+	for raw, want := range tests {
+		if got := NormalizeText(raw); got != want {
+			t.Errorf("%q: expected %q, got %q", raw, want, got)
+		}
+	}
+}
+
+func TestNormalizeKeepsOriginal(t *testing.T) {
+	xkcd := &XKCD{Title: "Tom &amp; Jerry", SafeTitle: "Tom and Jerry"}
+	xkcd.Normalize()
+
+	if xkcd.Title != "Tom & Jerry" || xkcd.Original["Title"] != "Tom &amp; Jerry" {
+		t.Errorf("expected the cleaned title and the original, got %q, %v", xkcd.Title, xkcd.Original)
+	}
+
+	if _, ok := xkcd.Original["SafeTitle"]; ok || len(xkcd.Original) != 1 {
+		t.Errorf("expected only the changed fields in Original, got %v", xkcd.Original)
+	}
+}
+
+func TestUpstreamDiffNormalizesStored(t *testing.T) {
+	stored := &XKCD{Number: 1, Title: "Rock &amp; Roll", ImageAlt: "cafÃ©"}
+	downloaded := &XKCD{Number: 1, Title: "Rock & Roll", ImageAlt: "café", News: "new"}
+
+	if got := UpstreamDiff(stored, downloaded); len(got) != 1 || got[0].Field != "News" {
+		t.Errorf("expected only the news to change, got %+v", got)
+	}
+
+	c := Comics{}
+	c.Load([]XKCD{*stored})
+	c.Refresh(downloaded)
+
+	if history := c.History(1); len(history) != 1 || len(history[0].Changes) != 1 {
+		t.Errorf("expected a single change in the history, got %+v", history)
+	}
+}
@@ -0,0 +1,44 @@
+package comic
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// knownKeys are the keys of the JSON document stored in the XKCD fields.
+var knownKeys = func() map[string]bool {
+	result := make(map[string]bool)
+	t := reflect.TypeOf(XKCD{})
+
+	for i := 0; i < t.NumField(); i++ {
+		if name := strings.Split(t.Field(i).Tag.Get("json"), ",")[0]; name != "" && name != "-" {
+			result[name] = true
+		}
+	}
+
+	return result
+}()
+
+// Extra returns the keys of the JSON document of the comic that have no XKCD field, such as extra_parts
+// of the interactive comics. It returns nil for the comics downloaded before the payload was stored.
+func (xkcd *XKCD) Extra() (map[string]json.RawMessage, error) {
+	if len(xkcd.Payload) == 0 {
+		return nil, nil
+	}
+
+	var document map[string]json.RawMessage
+
+	if err := json.Unmarshal(xkcd.Payload, &document); err != nil {
+		return nil, fmt.Errorf("extra: %v", err)
+	}
+
+	for key := range document {
+		if knownKeys[key] {
+			delete(document, key)
+		}
+	}
+
+	return document, nil
+}
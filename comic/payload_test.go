@@ -0,0 +1,55 @@
+package comic
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"testing"
+
+	"xkcd2/webclient"
+	"xkcd2/webclient/mocks"
+)
+
+func TestFetchKeepsPayload(t *testing.T) {
+	saved := webclient.Client
+	defer func() { webclient.Client = saved }()
+
+	body := `{"num": 1350, "title": "Lorenz", "extra_parts": {"headerextra": "", "pre": "<div>"}}`
+
+	webclient.Client = &mocks.MockClient{}
+	mocks.GetDoFunc = func(req *http.Request) (*http.Response, error) {
+		return &http.Response{StatusCode: http.StatusOK, Body: ioutil.NopCloser(bytes.NewReader([]byte(body)))}, nil
+	}
+
+	xkcd, err := fetch("test-url")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(xkcd.Payload) != body {
+		t.Errorf("expected the payload to be kept, got %s", xkcd.Payload)
+	}
+
+	extra, err := xkcd.Extra()
+
+	if err != nil || len(extra) != 1 || string(extra["extra_parts"]) != `{"headerextra": "", "pre": "<div>"}` {
+		t.Errorf("expected only extra_parts, got %v, %v", extra, err)
+	}
+
+	if extra, err := (&XKCD{}).Extra(); extra != nil || err != nil {
+		t.Errorf("expected nil without a payload, got %v, %v", extra, err)
+	}
+}
+
+func TestGetCopiesPayload(t *testing.T) {
+	c := Comics{}
+	c.Load([]XKCD{{Number: 1, Payload: []byte(`{"num":1}`)}})
+
+	_, xkcd := c.Get(1)
+	xkcd.Payload[0] = 'x'
+
+	if _, stored := c.Get(1); string(stored.Payload) != `{"num":1}` {
+		t.Errorf("expected the stored payload to be unchanged, got %s", stored.Payload)
+	}
+}
@@ -3,6 +3,7 @@ package comic
 import (
 	"encoding/json"
 	"fmt"
+	"sort"
 	"xkcd2/tools/logger"
 	"xkcd2/webclient"
 )
@@ -22,8 +23,15 @@ func downloadImage(imageUrl string) ([]byte, error) {
 
 // fetch perfroms GET operation on url and it unmarshalls the JSON document into XKCD object.
 func fetch(url string) (*XKCD, error) {
+	return fetchConditional(url, webclient.Validators{})
+}
+
+// fetchConditional works as fetch, but it sends the validators of the previous download. If the comic
+// has not changed, the error is webclient.ErrNotModified. The validators and the JSON document of the response are
+// stored in the result and its text fields are normalized, see XKCD.Normalize.
+func fetchConditional(url string, validators webclient.Validators) (*XKCD, error) {
 	defer logger.Trace(fmt.Sprintf("func fetch(%s)", url))()
-	result, err := webclient.Get(url)
+	result, validators, err := webclient.GetConditional(url, validators)
 
 	if err != nil {
 		return nil, err
@@ -35,39 +43,64 @@ func fetch(url string) (*XKCD, error) {
 		return nil, fmt.Errorf("fetch: %v", err)
 	}
 
+	xkcd.Validators = validators
+	xkcd.Payload = result
+	xkcd.Normalize()
+
 	return xkcd, nil
 }
 
-// getBinarySearch is a recursive algorithm for locating the comicNum based on startIx and endIx index values.
-// If the comic is found it will return the index location and an object, otherwise it returns -1 and nil.
-// This method will be invoked from Get only if Comics.sorted is set to true.
-func (c *Comics) getBinarySearch(startIx, endIx int, comicNum int) (int, *XKCD) {
-	if startIx > endIx {
-		return -1, nil
+// insertNumber adds comicNum to the ordered numbers. Comics usually arrive in ascending order,
+// in which case the number is appended. The caller must hold the write lock.
+func (c *Comics) insertNumber(comicNum int) {
+	size := len(c.numbers)
+
+	if size == 0 || c.numbers[size-1] < comicNum {
+		c.numbers = append(c.numbers, comicNum)
+		return
 	}
 
-	mid := (endIx + startIx) / 2
+	pos := sort.SearchInts(c.numbers, comicNum)
+
+	c.numbers = append(c.numbers, 0)
+	copy(c.numbers[pos+1:], c.numbers[pos:])
+	c.numbers[pos] = comicNum
+}
+
+// snapshot returns a copy of the comics ordered by the comic number.
+func (c *Comics) snapshot() []XKCD {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
 
-	if c.comics[mid].Number == comicNum {
-		return mid, &c.comics[mid]
-	} else if c.comics[mid].Number < comicNum {
-		startIx = mid + 1
-	} else {
-		endIx = mid - 1
+	result := make([]XKCD, 0, len(c.numbers))
+
+	for _, num := range c.numbers {
+		result = append(result, c.comics[num].clone())
 	}
 
-	return c.getBinarySearch(startIx, endIx, comicNum)
+	return result
 }
 
-// getSequentialSearch will scan through the Comics.comics slice from start to finish
-// in order to locate the comicNum. If the comic is found it returns index location and XKCD object,
-// otherwise it will return -1 and nil.
-func (c *Comics) getSequentialSearch(comicNum int) (int, *XKCD) {
-	for i, item := range c.comics {
-		if item.Number == comicNum {
-			return i, &item
+// clone returns a copy of xkcd that does not share the Assets, Original and Payload with it, so the copy can be changed
+// without changing a stored comic.
+func (xkcd *XKCD) clone() XKCD {
+	result := *xkcd
+
+	if xkcd.Assets != nil {
+		result.Assets = append([]Asset(nil), xkcd.Assets...)
+	}
+
+	if xkcd.Payload != nil {
+		result.Payload = append([]byte(nil), xkcd.Payload...)
+	}
+
+	if xkcd.Original != nil {
+		result.Original = make(map[string]string, len(xkcd.Original))
+
+		for field, value := range xkcd.Original {
+			result.Original[field] = value
 		}
 	}
 
-	return -1, nil
+	return result
 }
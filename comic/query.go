@@ -0,0 +1,252 @@
+package comic
+
+import (
+	"encoding/base64"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Order defines how the comics returned by a Query are ordered.
+type Order int
+
+// Supported orders of a Query. Ties are always broken by the comic number.
+const (
+	OrderByNumber Order = iota
+	OrderByDate
+	OrderByTitle
+)
+
+// Query selects comics from a snapshot of a collection. Filters and ordering are applied lazily when
+// the results are requested with All, Each, Count or Page. The query methods return the query itself,
+// so they can be chained:
+//
+//	page, next, err := comics.Query().Range(1000, 1100).OrderBy(OrderByTitle, false).Page("", 20)
+//
+// The results are copies, changing them does not change the collection.
+type Query struct {
+	items   []XKCD
+	filters []func(xkcd *XKCD) bool
+	order   Order
+	desc    bool
+	limit   int
+}
+
+// Query returns a query over a snapshot of the collection.
+func (c *Comics) Query() *Query {
+	return NewQuery(c.snapshot())
+}
+
+// NewQuery returns a query over items. The items are not modified.
+func NewQuery(items []XKCD) *Query {
+	return &Query{items: items}
+}
+
+// ParseOrder converts the name of an order (number, date or title) into Order.
+func ParseOrder(name string) (Order, error) {
+	switch strings.ToLower(name) {
+	case "", "number", "num":
+		return OrderByNumber, nil
+	case "date":
+		return OrderByDate, nil
+	case "title":
+		return OrderByTitle, nil
+	}
+
+	return OrderByNumber, fmt.Errorf("unknown order %q", name)
+}
+
+// Range keeps the comics with a number between from and to, both included. A bound of 0 or less is not applied.
+func (q *Query) Range(from, to int) *Query {
+	return q.Filter(func(xkcd *XKCD) bool {
+		return (from <= 0 || xkcd.Number >= from) && (to <= 0 || xkcd.Number <= to)
+	})
+}
+
+// Filter keeps the comics for which match returns true.
+func (q *Query) Filter(match func(xkcd *XKCD) bool) *Query {
+	q.filters = append(q.filters, match)
+
+	return q
+}
+
+// OrderBy sets the order of the results. The default order is ascending by the comic number.
+// When ordering by date, the comics with a malformed date come last, also in descending order.
+func (q *Query) OrderBy(order Order, descending bool) *Query {
+	q.order = order
+	q.desc = descending
+
+	return q
+}
+
+// Limit returns at most n results. A limit of 0 or less returns all the results.
+func (q *Query) Limit(n int) *Query {
+	q.limit = n
+
+	return q
+}
+
+// All returns the matching comics.
+func (q *Query) All() []XKCD {
+	return q.run()
+}
+
+// Count returns the number of matching comics.
+func (q *Query) Count() int {
+	return len(q.run())
+}
+
+// Each calls fn for every matching comic until fn returns false.
+func (q *Query) Each(fn func(xkcd XKCD) bool) {
+	for _, xkcd := range q.run() {
+		if !fn(xkcd) {
+			return
+		}
+	}
+}
+
+// Page returns up to size matching comics that come after cursor, together with the cursor of the next page.
+// An empty cursor starts from the first comic, an empty next cursor means there are no more comics.
+// The cursor refers to the last comic of a page rather than to a position, so the pages stay consistent
+// when comics are added between the calls. An error is returned if the cursor is invalid or its comic
+// no longer matches the query.
+func (q *Query) Page(cursor string, size int) ([]XKCD, string, error) {
+	results := q.run()
+	start := 0
+
+	if cursor != "" {
+		last, err := decodeCursor(cursor)
+
+		if err != nil {
+			return nil, "", err
+		}
+
+		start = -1
+
+		for i := range results {
+			if results[i].Number == last {
+				start = i + 1
+				break
+			}
+		}
+
+		if start < 0 {
+			return nil, "", fmt.Errorf("query page: cursor comic %d is not in the results", last)
+		}
+	}
+
+	if size <= 0 {
+		size = len(results)
+	}
+
+	end := start + size
+
+	if end >= len(results) {
+		return results[start:], "", nil
+	}
+
+	return results[start:end], encodeCursor(results[end-1].Number), nil
+}
+
+// run applies the filters, the order and the limit.
+func (q *Query) run() []XKCD {
+	results := make([]XKCD, 0, len(q.items))
+
+	for i := range q.items {
+		if q.matches(&q.items[i]) {
+			results = append(results, q.items[i])
+		}
+	}
+
+	less := q.less(results)
+
+	sort.SliceStable(results, func(i, j int) bool {
+		if q.desc {
+			return less(j, i)
+		}
+
+		return less(i, j)
+	})
+
+	if q.limit > 0 && q.limit < len(results) {
+		results = results[:q.limit]
+	}
+
+	return results
+}
+
+func (q *Query) matches(xkcd *XKCD) bool {
+	for _, match := range q.filters {
+		if !match(xkcd) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// less returns the comparison of results for the query order.
+func (q *Query) less(results []XKCD) func(i, j int) bool {
+	byNumber := func(i, j int) bool { return results[i].Number < results[j].Number }
+
+	switch q.order {
+	case OrderByDate:
+		dates := make(map[int]int64, len(results))
+
+		for i := range results {
+			if date, err := results[i].Date(); err == nil {
+				dates[results[i].Number] = date.Unix()
+			}
+		}
+
+		return func(i, j int) bool {
+			di, iok := dates[results[i].Number]
+			dj, jok := dates[results[j].Number]
+
+			// run reverses the comparison for a descending order, so the malformed dates are reversed
+			// here too in order to keep them last in both directions
+			if iok != jok {
+				return iok != q.desc
+			}
+
+			if di != dj {
+				return di < dj
+			}
+
+			return byNumber(i, j)
+		}
+	case OrderByTitle:
+		return func(i, j int) bool {
+			ti, tj := strings.ToLower(results[i].Title), strings.ToLower(results[j].Title)
+
+			if ti != tj {
+				return ti < tj
+			}
+
+			return byNumber(i, j)
+		}
+	}
+
+	return byNumber
+}
+
+func encodeCursor(comicNum int) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(strconv.Itoa(comicNum)))
+}
+
+func decodeCursor(cursor string) (int, error) {
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+
+	if err != nil {
+		return 0, fmt.Errorf("query page: invalid cursor %q", cursor)
+	}
+
+	result, err := strconv.Atoi(string(data))
+
+	if err != nil {
+		return 0, fmt.Errorf("query page: invalid cursor %q", cursor)
+	}
+
+	return result, nil
+}
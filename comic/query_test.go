@@ -0,0 +1,144 @@
+package comic
+
+import "testing"
+
+func setupQueryComics() *Comics {
+	c := &Comics{}
+	c.Load([]XKCD{
+		{Number: 1, Title: "Barrel", Year: "2006", Month: "1", Day: "1"},
+		{Number: 2, Title: "petit trees", Year: "2005", Month: "1", Day: "1"},
+		{Number: 3, Title: "Island", Year: "2007", Month: "1", Day: "1"},
+		{Number: 4, Title: "Landscape", Year: "x"},
+		{Number: 5, Title: "Blown apart", Year: "2006", Month: "6", Day: "1"},
+	})
+
+	return c
+}
+
+func numbers(items []XKCD) []int {
+	result := make([]int, 0, len(items))
+
+	for _, xkcd := range items {
+		result = append(result, xkcd.Number)
+	}
+
+	return result
+}
+
+func equalNumbers(got []XKCD, want ...int) bool {
+	nums := numbers(got)
+
+	if len(nums) != len(want) {
+		return false
+	}
+
+	for i := range want {
+		if nums[i] != want[i] {
+			return false
+		}
+	}
+
+	return true
+}
+
+func TestQueryRange(t *testing.T) {
+	got := setupQueryComics().Query().Range(2, 4).All()
+
+	if !equalNumbers(got, 2, 3, 4) {
+		t.Errorf("expected [2 3 4], got %v", numbers(got))
+	}
+}
+
+func TestQueryFilterAndOrder(t *testing.T) {
+	got := setupQueryComics().Query().
+		Filter(func(xkcd *XKCD) bool { return xkcd.Number != 3 }).
+		OrderBy(OrderByTitle, false).
+		All()
+
+	if !equalNumbers(got, 1, 5, 4, 2) {
+		t.Errorf("expected [1 5 4 2], got %v", numbers(got))
+	}
+}
+
+func TestQueryOrderByDate(t *testing.T) {
+	got := setupQueryComics().Query().OrderBy(OrderByDate, false).All()
+
+	if !equalNumbers(got, 2, 1, 5, 3, 4) {
+		t.Errorf("expected [2 1 5 3 4], got %v", numbers(got))
+	}
+
+	got = setupQueryComics().Query().OrderBy(OrderByDate, true).All()
+
+	if !equalNumbers(got, 3, 5, 1, 2, 4) {
+		t.Errorf("expected [3 5 1 2 4] with the malformed date last, got %v", numbers(got))
+	}
+}
+
+func TestQueryEachStops(t *testing.T) {
+	count := 0
+
+	setupQueryComics().Query().Each(func(xkcd XKCD) bool {
+		count++
+		return count < 2
+	})
+
+	if count != 2 {
+		t.Errorf("expected 2 calls, got %d", count)
+	}
+}
+
+func TestQueryPage(t *testing.T) {
+	c := setupQueryComics()
+
+	page, next, err := c.Query().Page("", 2)
+
+	if err != nil || !equalNumbers(page, 1, 2) || next == "" {
+		t.Fatalf("expected [1 2] and a cursor, got %v, %q, %v", numbers(page), next, err)
+	}
+
+	// a comic added before the cursor does not shift the next page
+	c.Add(&XKCD{Number: 0})
+
+	page, next, err = c.Query().Page(next, 2)
+
+	if err != nil || !equalNumbers(page, 3, 4) {
+		t.Fatalf("expected [3 4], got %v, %v", numbers(page), err)
+	}
+
+	page, next, err = c.Query().Page(next, 2)
+
+	if err != nil || !equalNumbers(page, 5) || next != "" {
+		t.Errorf("expected the last page [5], got %v, %q, %v", numbers(page), next, err)
+	}
+
+	if _, _, err := c.Query().Page("not a cursor", 2); err == nil {
+		t.Errorf("expected error for an invalid cursor")
+	}
+}
+
+func TestQuerySnapshot(t *testing.T) {
+	c := setupQueryComics()
+	q := c.Query()
+
+	c.Add(&XKCD{Number: 6})
+
+	if q.Count() != 5 {
+		t.Errorf("expected the query to use a snapshot, got %d comics", q.Count())
+	}
+
+	q.All()[0].Title = "changed"
+
+	if _, xkcd := c.Get(1); xkcd.Title != "Barrel" {
+		t.Errorf("expected the collection not to change through the results")
+	}
+}
+
+func TestParseOrder(t *testing.T) {
+	if order, err := ParseOrder("date"); err != nil || order != OrderByDate {
+		t.Errorf("expected OrderByDate, got %v, %v", order, err)
+	}
+
+	if _, err := ParseOrder("size"); err == nil {
+		t.Errorf("expected error for an unknown order")
+	}
+}
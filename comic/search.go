@@ -0,0 +1,438 @@
+package comic
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+
+	"xkcd2/tools/util"
+)
+
+// ExtraField builds the filter of a field term the query language does not know, such as the fields of the
+// annotations kept outside of the comic package. ok is false if the field is unknown.
+type ExtraField func(field, value string) (filter func(xkcd *XKCD) bool, ok bool, err error)
+
+// SearchFields are the fields of the query language.
+var SearchFields = []string{"title", "alt", "transcript", "text", "speaker", "num", "year", "month", "day", "date"}
+
+// ParseSearch parses a query of the search language into a Query filter. A query is made of terms:
+//
+//	word             the word in the title, the alt text or the transcript, case-insensitively
+//	"a phrase"       the phrase in the same fields
+//	title:word       the word in one field: title, alt, transcript, text (all three) or speaker
+//	title:"a phrase" the phrase in one field
+//	num:1000..1100   a number range, also num:>=1000, num:<10 and num:404; num, year, month and day
+//	year:2015        the comics of a year
+//	date:2015-01..2015-06-30  a date range with dates as YYYY, YYYY-MM or YYYY-MM-DD, or a single date
+//
+// Terms are combined with AND, which is the default between terms, and OR, AND binding tighter.
+// NOT term and -term negate a term, and parentheses group terms. Fields unknown to the language are
+// passed to extra, which may be nil.
+func ParseSearch(query string, extra ExtraField) (func(xkcd *XKCD) bool, error) {
+	tokens, err := tokenize(query)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if len(tokens) == 0 {
+		return nil, fmt.Errorf("search: empty query")
+	}
+
+	p := &searchParser{extra: extra}
+
+	for _, token := range tokens {
+		p.tokens = append(p.tokens, token.text)
+	}
+
+	filter, err := p.parseOr()
+
+	if err != nil {
+		return nil, err
+	}
+
+	if p.pos < len(p.tokens) {
+		return nil, fmt.Errorf("search: unexpected %q", p.tokens[p.pos])
+	}
+
+	return filter, nil
+}
+
+type searchParser struct {
+	tokens []string
+	pos    int
+	extra  ExtraField
+}
+
+func (p *searchParser) peek() string {
+	if p.pos < len(p.tokens) {
+		return p.tokens[p.pos]
+	}
+
+	return ""
+}
+
+func (p *searchParser) parseOr() (func(xkcd *XKCD) bool, error) {
+	left, err := p.parseAnd()
+
+	if err != nil {
+		return nil, err
+	}
+
+	for p.peek() == "OR" {
+		p.pos++
+		right, err := p.parseAnd()
+
+		if err != nil {
+			return nil, err
+		}
+
+		l := left
+		left = func(xkcd *XKCD) bool { return l(xkcd) || right(xkcd) }
+	}
+
+	return left, nil
+}
+
+func (p *searchParser) parseAnd() (func(xkcd *XKCD) bool, error) {
+	left, err := p.parseUnary()
+
+	if err != nil {
+		return nil, err
+	}
+
+	for next := p.peek(); next != "" && next != "OR" && next != ")"; next = p.peek() {
+		if next == "AND" {
+			p.pos++
+		}
+
+		right, err := p.parseUnary()
+
+		if err != nil {
+			return nil, err
+		}
+
+		l := left
+		left = func(xkcd *XKCD) bool { return l(xkcd) && right(xkcd) }
+	}
+
+	return left, nil
+}
+
+func (p *searchParser) parseUnary() (func(xkcd *XKCD) bool, error) {
+	token := p.peek()
+
+	switch {
+	case token == "NOT" || token == "-":
+		p.pos++
+		operand, err := p.parseUnary()
+
+		if err != nil {
+			return nil, err
+		}
+
+		return func(xkcd *XKCD) bool { return !operand(xkcd) }, nil
+	case token == "(":
+		p.pos++
+		inner, err := p.parseOr()
+
+		if err != nil {
+			return nil, err
+		}
+
+		if p.peek() != ")" {
+			return nil, fmt.Errorf("search: missing )")
+		}
+
+		p.pos++
+
+		return inner, nil
+	case token == "" || token == ")" || token == "OR" || token == "AND":
+		return nil, fmt.Errorf("search: expected a term, got %q", token)
+	}
+
+	p.pos++
+
+	return p.parseTerm(token)
+}
+
+// parseTerm converts a single term into a filter.
+func (p *searchParser) parseTerm(term string) (func(xkcd *XKCD) bool, error) {
+	field, value := "", term
+
+	if i := strings.Index(term, ":"); i > 0 && isFieldName(term[:i]) {
+		field, value = strings.ToLower(term[:i]), term[i+1:]
+	}
+
+	phrase := len(value) >= 2 && strings.HasPrefix(value, `"`) && strings.HasSuffix(value, `"`)
+
+	if phrase {
+		value = value[1 : len(value)-1]
+	}
+
+	if value == "" {
+		return nil, fmt.Errorf("search: empty value in %q", term)
+	}
+
+	switch field {
+	case "", "text":
+		return textFilter(value, func(xkcd *XKCD) []string { return []string{xkcd.Title, xkcd.ImageAlt, xkcd.Transcript} }), nil
+	case "title":
+		return textFilter(value, func(xkcd *XKCD) []string { return []string{xkcd.Title, xkcd.SafeTitle} }), nil
+	case "alt":
+		return textFilter(value, func(xkcd *XKCD) []string { return []string{xkcd.ImageAlt} }), nil
+	case "transcript":
+		return textFilter(value, func(xkcd *XKCD) []string { return []string{xkcd.Transcript} }), nil
+	case "speaker":
+		return SpeakerFilter(value), nil
+	case "num", "year", "month", "day":
+		return numberFilter(field, value)
+	case "date":
+		return dateFilter(value)
+	}
+
+	if p.extra != nil {
+		filter, ok, err := p.extra(field, value)
+
+		if err != nil {
+			return nil, fmt.Errorf("search: %v", err)
+		}
+
+		if ok {
+			return filter, nil
+		}
+	}
+
+	return nil, fmt.Errorf("search: unknown field %q", field)
+}
+
+// textFilter matches the comics with value in one of the fields, case-insensitively and ignoring
+// the differences in white space.
+func textFilter(value string, fields func(xkcd *XKCD) []string) func(xkcd *XKCD) bool {
+	needle := normalizeSpace(value)
+
+	return func(xkcd *XKCD) bool {
+		for _, text := range fields(xkcd) {
+			if strings.Contains(normalizeSpace(text), needle) {
+				return true
+			}
+		}
+
+		return false
+	}
+}
+
+// numberFilter matches a numeric field against a number, a range a..b (either end may be missing)
+// or a comparison such as >=10.
+func numberFilter(field, value string) (func(xkcd *XKCD) bool, error) {
+	low, high, err := util.ParseRange(value)
+
+	if err != nil {
+		return nil, fmt.Errorf("search: %s: %v", field, err)
+	}
+
+	return func(xkcd *XKCD) bool {
+		var n int
+
+		if field == "num" {
+			n = xkcd.Number
+		} else {
+			date, err := xkcd.Date()
+
+			if err != nil {
+				return false
+			}
+
+			switch field {
+			case "year":
+				n = date.Year()
+			case "month":
+				n = int(date.Month())
+			default:
+				n = date.Day()
+			}
+		}
+
+		return n >= low && n <= high
+	}, nil
+}
+
+// dateFilter matches the publication date against a date or a date range. A partial date covers
+// the whole year or month.
+func dateFilter(value string) (func(xkcd *XKCD) bool, error) {
+	var from, to time.Time
+
+	if i := strings.Index(value, ".."); i >= 0 {
+		var err error
+
+		if left := value[:i]; left != "" {
+			if from, _, err = parseDatePeriod(left); err != nil {
+				return nil, err
+			}
+		}
+
+		if right := value[i+2:]; right != "" {
+			if _, to, err = parseDatePeriod(right); err != nil {
+				return nil, err
+			}
+		}
+	} else {
+		var err error
+
+		if from, to, err = parseDatePeriod(value); err != nil {
+			return nil, err
+		}
+	}
+
+	return DateFilter(func(date time.Time) bool {
+		return !date.Before(from) && (to.IsZero() || date.Before(to))
+	}), nil
+}
+
+// parseDatePeriod parses YYYY, YYYY-MM or YYYY-MM-DD and returns the start of the period and the start of the next one.
+func parseDatePeriod(value string) (time.Time, time.Time, error) {
+	for _, layout := range []struct {
+		format        string
+		years, months int
+		days          int
+	}{{"2006-01-02", 0, 0, 1}, {"2006-01", 0, 1, 0}, {"2006", 1, 0, 0}} {
+		if start, err := time.Parse(layout.format, value); err == nil {
+			return start, start.AddDate(layout.years, layout.months, layout.days), nil
+		}
+	}
+
+	return time.Time{}, time.Time{}, fmt.Errorf("search: invalid date %q, use YYYY, YYYY-MM or YYYY-MM-DD", value)
+}
+
+// RewriteSearch returns query with the text of every word or phrase replaced by rewrite, for example to
+// correct its spelling. The terms without a field and the terms of the text fields title, alt, transcript
+// and text are rewritten, a phrase without its quotes. The other fields, the operators and the rest of the
+// query are kept as they are.
+func RewriteSearch(query string, rewrite func(text string) string) (string, error) {
+	tokens, err := tokenize(query)
+
+	if err != nil {
+		return "", err
+	}
+
+	runes := []rune(query)
+	var result strings.Builder
+	last := 0
+
+	for _, token := range tokens {
+		prefix, value := "", token.text
+
+		switch value {
+		case "(", ")", "-", "AND", "OR", "NOT":
+			continue
+		}
+
+		if i := strings.Index(value, ":"); i > 0 && isFieldName(value[:i]) {
+			switch strings.ToLower(value[:i]) {
+			case "title", "alt", "transcript", "text":
+				prefix, value = value[:i+1], value[i+1:]
+			default:
+				continue
+			}
+		}
+
+		if len(value) >= 2 && strings.HasPrefix(value, `"`) && strings.HasSuffix(value, `"`) {
+			value = `"` + rewrite(value[1:len(value)-1]) + `"`
+		} else if value != "" {
+			value = rewrite(value)
+		}
+
+		result.WriteString(string(runes[last:token.start]))
+		result.WriteString(prefix + value)
+		last = token.end
+	}
+
+	result.WriteString(string(runes[last:]))
+
+	return result.String(), nil
+}
+
+// FreeText returns the words and phrases of the text terms of query, see RewriteSearch, without the fields,
+// operators and quotes, or "" if the query is invalid.
+func FreeText(query string) string {
+	var terms []string
+
+	if _, err := RewriteSearch(query, func(text string) string {
+		terms = append(terms, text)
+		return text
+	}); err != nil {
+		return ""
+	}
+
+	return strings.Join(terms, " ")
+}
+
+// searchToken is a token of a query with its position in the runes of the query.
+type searchToken struct {
+	text       string
+	start, end int
+}
+
+// tokenize splits the query into parentheses, operators and terms. A quoted phrase is a single term,
+// also after a field name, as in title:"a phrase". A - before a term is a separate token.
+func tokenize(query string) ([]searchToken, error) {
+	var tokens []searchToken
+	runes := []rune(query)
+
+	for i := 0; i < len(runes); {
+		r := runes[i]
+
+		switch {
+		case unicode.IsSpace(r):
+			i++
+			continue
+		case r == '(' || r == ')':
+			tokens = append(tokens, searchToken{string(r), i, i + 1})
+			i++
+			continue
+		case r == '-' && i+1 < len(runes) && !unicode.IsSpace(runes[i+1]) && (i == 0 || unicode.IsSpace(runes[i-1]) || runes[i-1] == '('):
+			tokens = append(tokens, searchToken{"-", i, i + 1})
+			i++
+			continue
+		}
+
+		start := i
+		quoted := false
+
+		for ; i < len(runes); i++ {
+			if runes[i] == '"' {
+				quoted = !quoted
+				continue
+			}
+
+			if !quoted && (unicode.IsSpace(runes[i]) || runes[i] == '(' || runes[i] == ')') {
+				break
+			}
+		}
+
+		if quoted {
+			return nil, fmt.Errorf("search: missing closing quote")
+		}
+
+		tokens = append(tokens, searchToken{string(runes[start:i]), start, i})
+	}
+
+	return tokens, nil
+}
+
+// isFieldName returns true for names made of letters and underscores.
+func isFieldName(name string) bool {
+	for _, r := range name {
+		if !unicode.IsLetter(r) && r != '_' {
+			return false
+		}
+	}
+
+	return name != ""
+}
+
+// normalizeSpace returns text in lower case with the runs of white space replaced by a single space.
+func normalizeSpace(text string) string {
+	return strings.Join(strings.Fields(strings.ToLower(text)), " ")
+}
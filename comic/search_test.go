@@ -0,0 +1,116 @@
+package comic
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseSearch(t *testing.T) {
+	var c Comics
+
+	c.Load([]XKCD{
+		{Number: 1, Title: "Barrel", ImageAlt: "Don't we all.", Year: "2006", Month: "1", Day: "1"},
+		{Number: 2, Title: "Petit Trees", ImageAlt: "Pine trees and a sheep", Year: "2006", Month: "1", Day: "1"},
+		{Number: 1000, Title: "1000 Comics", ImageAlt: "Spelling out the number", Year: "2012", Month: "1", Day: "6"},
+		{Number: 1050, Title: "Forgot Algebra", Transcript: "Megan: I forgot algebra.", Year: "2012", Month: "3", Day: "16"},
+		{Number: 1500, Title: "Upside-Down Map", ImageAlt: "Trees and a map", Year: "2015", Month: "3", Day: "2"},
+	})
+
+	extra := func(field, value string) (func(xkcd *XKCD) bool, bool, error) {
+		if field != "even" {
+			return nil, false, nil
+		}
+
+		return func(xkcd *XKCD) bool { return xkcd.Number%2 == 0 }, true, nil
+	}
+
+	tests := map[string][]int{
+		"trees":                       {2, 1500},
+		"TREES map":                   {1500},
+		"trees AND map":               {1500},
+		"barrel OR algebra":           {1, 1050},
+		`"and a map"`:                 {1500},
+		`alt:"pine   trees"`:          {2},
+		"title:trees":                 {2},
+		"transcript:algebra":          {1050},
+		"speaker:megan":               {1050},
+		"trees -map":                  {2},
+		"trees NOT map":               {2},
+		"num:1000..1100":              {1000, 1050},
+		"num:>=1050":                  {1050, 1500},
+		"num:..2":                     {1, 2},
+		"year:2012":                   {1000, 1050},
+		"month:3":                     {1050, 1500},
+		"date:2012-03..2015":          {1050, 1500},
+		"date:2012-01-06":             {1000},
+		"(barrel OR trees) year:2006": {1, 2},
+		"-(year:2006 OR year:2012)":   {1500},
+		"trees even:":                 nil,
+		"trees even:x":                {2, 1500},
+		"barrel algebra OR map trees": {1500},
+	}
+
+	for query, want := range tests {
+		filter, err := ParseSearch(query, extra)
+
+		if query == "trees even:" {
+			if err == nil {
+				t.Errorf("%s: expected an error for an empty value", query)
+			}
+
+			continue
+		}
+
+		if err != nil {
+			t.Errorf("%s: %v", query, err)
+			continue
+		}
+
+		var got []int
+
+		for _, xkcd := range c.Query().Filter(filter).All() {
+			got = append(got, xkcd.Number)
+		}
+
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("%s: expected %v, got %v", query, want, got)
+		}
+	}
+}
+
+func TestParseSearchErrors(t *testing.T) {
+	for _, query := range []string{"", "(trees", "trees)", `"trees`, "OR trees", "num:x", "date:2015-13", "color:red"} {
+		if _, err := ParseSearch(query, nil); err == nil {
+			t.Errorf("%q: expected an error", query)
+		}
+	}
+}
+
+func TestRewriteSearch(t *testing.T) {
+	upper := func(text string) string { return strings.ToUpper(text) }
+
+	tests := map[string]string{
+		`bobby tabels`:                          `BOBBY TABELS`,
+		`title:"bobby tabels" year:2015`:        `title:"BOBBY TABELS" year:2015`,
+		`(tabels OR "little  bobby") -num:1..9`: `(TABELS OR "LITTLE  BOBBY") -num:1..9`,
+		`NOT alt:drop speaker:megan`:            `NOT alt:DROP speaker:megan`,
+		`-tables AND tag:sql`:                   `-TABLES AND tag:sql`,
+	}
+
+	for query, want := range tests {
+		got, err := RewriteSearch(query, upper)
+
+		if err != nil || got != want {
+			t.Errorf("%s: expected %q, got %q (%v)", query, want, got, err)
+		}
+	}
+
+	if got := FreeText(`title:"bobby tabels" year:2015 OR -drop`); got != "bobby tabels drop" {
+		t.Errorf("expected the text terms, got %q", got)
+	}
+
+	if _, err := RewriteSearch(`"tabels`, upper); err == nil {
+		t.Errorf("expected an error for a missing quote")
+	}
+}
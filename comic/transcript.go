@@ -0,0 +1,211 @@
+package comic
+
+import (
+	"regexp"
+	"strings"
+)
+
+// LineKind is the kind of a transcript line.
+type LineKind int
+
+const (
+	TextLine     LineKind = iota // text that follows none of the conventions, such as signs and captions
+	SceneLine                    // [[scene description]]
+	DialogueLine                 // Speaker: line
+	NoteLine                     // ((note of the transcriber))
+)
+
+// TranscriptLine is a single line of a transcript. Speaker is set only for dialogue lines.
+type TranscriptLine struct {
+	Kind    LineKind
+	Speaker string
+	Text    string
+}
+
+// Panel is a group of transcript lines.
+type Panel struct {
+	Lines []TranscriptLine
+}
+
+// Transcript is a transcript parsed by ParseTranscript.
+type Transcript struct {
+	Panels    []Panel
+	TitleText string // the {{title text}} found in the transcript, it is usually the same as XKCD.ImageAlt
+}
+
+// speakerPattern matches the "Speaker: line" convention. The speaker is a short name that starts with
+// a letter, possibly with a note in parentheses, such as "Cueball (off-panel)".
+var speakerPattern = regexp.MustCompile(`^([\p{L}][\p{L}\p{N}'#&. -]{0,30}?(?:\s*\([^()]*\))?)\s*:\s+(.+)$`)
+
+// titleTextPrefix matches the label at the start of the title text, such as "Title text:" or "alt-text:".
+var titleTextPrefix = regexp.MustCompile(`(?i)^(title[ -]?text|alt[ -]?text|alt)\s*:\s*`)
+
+// ParseTranscript splits the raw transcript of a comic into panels and lines. The transcripts follow
+// loose conventions: [[scene descriptions]], {{title text}}, ((notes)) and "Speaker: line" for dialogues.
+// Panels are separated by blank lines, or else a scene description after other lines starts a new panel.
+func ParseTranscript(raw string) Transcript {
+	var result Transcript
+	var panel Panel
+
+	flush := func() {
+		if len(panel.Lines) > 0 {
+			result.Panels = append(result.Panels, panel)
+			panel = Panel{}
+		}
+	}
+
+	for _, line := range joinBrackets(strings.Split(strings.ReplaceAll(raw, "\r\n", "\n"), "\n")) {
+		line = strings.TrimSpace(line)
+
+		switch {
+		case line == "":
+			flush()
+		case enclosed(line, "{{", "}}"):
+			text := titleTextPrefix.ReplaceAllString(strings.TrimSpace(line[2:len(line)-2]), "")
+
+			if result.TitleText == "" {
+				result.TitleText = text
+			}
+		case enclosed(line, "[[", "]]"):
+			if len(panel.Lines) > 0 && panel.Lines[len(panel.Lines)-1].Kind != SceneLine {
+				flush()
+			}
+
+			panel.Lines = append(panel.Lines, TranscriptLine{Kind: SceneLine, Text: strings.TrimSpace(line[2 : len(line)-2])})
+		case enclosed(line, "((", "))"):
+			panel.Lines = append(panel.Lines, TranscriptLine{Kind: NoteLine, Text: strings.TrimSpace(line[2 : len(line)-2])})
+		default:
+			if match := speakerPattern.FindStringSubmatch(line); match != nil && isSpeaker(match[1], match[2]) {
+				panel.Lines = append(panel.Lines, TranscriptLine{Kind: DialogueLine, Speaker: strings.TrimSpace(match[1]), Text: match[2]})
+			} else {
+				panel.Lines = append(panel.Lines, TranscriptLine{Kind: TextLine, Text: line})
+			}
+		}
+	}
+
+	flush()
+
+	return result
+}
+
+// ParsedTranscript returns the parsed Transcript of the comic.
+func (xkcd *XKCD) ParsedTranscript() Transcript {
+	return ParseTranscript(xkcd.Transcript)
+}
+
+// Speakers returns the names of the speakers in the order they first speak. The notes in parentheses are
+// removed, so "Cueball (off-panel)" is the speaker Cueball.
+func (t Transcript) Speakers() []string {
+	var result []string
+	seen := make(map[string]bool)
+
+	for _, panel := range t.Panels {
+		for _, line := range panel.Lines {
+			if line.Kind != DialogueLine {
+				continue
+			}
+
+			name := speakerName(line.Speaker)
+
+			if key := strings.ToLower(name); !seen[key] {
+				seen[key] = true
+				result = append(result, name)
+			}
+		}
+	}
+
+	return result
+}
+
+// HasSpeaker returns true if name speaks in the transcript. Names are compared case-insensitively.
+func (t Transcript) HasSpeaker(name string) bool {
+	for _, speaker := range t.Speakers() {
+		if strings.EqualFold(speaker, strings.TrimSpace(name)) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// String renders the transcript as clean text: panels separated by blank lines, scene descriptions
+// in brackets and the dialogues as "Speaker: line".
+func (t Transcript) String() string {
+	var sb strings.Builder
+
+	for i, panel := range t.Panels {
+		if i > 0 {
+			sb.WriteString("\n")
+		}
+
+		for _, line := range panel.Lines {
+			switch line.Kind {
+			case SceneLine:
+				sb.WriteString("[" + line.Text + "]\n")
+			case DialogueLine:
+				sb.WriteString(line.Speaker + ": " + line.Text + "\n")
+			case NoteLine:
+				sb.WriteString("(" + line.Text + ")\n")
+			default:
+				sb.WriteString(line.Text + "\n")
+			}
+		}
+	}
+
+	return sb.String()
+}
+
+// SpeakerFilter returns a Query filter matching the comics where name speaks.
+func SpeakerFilter(name string) func(xkcd *XKCD) bool {
+	return func(xkcd *XKCD) bool {
+		return strings.Contains(strings.ToLower(xkcd.Transcript), strings.ToLower(name)) &&
+			xkcd.ParsedTranscript().HasSpeaker(name)
+	}
+}
+
+// isSpeaker tells the "Speaker: line" convention apart from other text with a colon, such as a URL or
+// a sentence. Speakers have at most three words.
+func isSpeaker(speaker, text string) bool {
+	return len(strings.Fields(speakerName(speaker))) <= 3 && !strings.HasPrefix(text, "//")
+}
+
+// speakerName returns the speaker without the note in parentheses.
+func speakerName(speaker string) string {
+	if i := strings.Index(speaker, "("); i > 0 {
+		return strings.TrimSpace(speaker[:i])
+	}
+
+	return speaker
+}
+
+// enclosed returns true if line starts with open and ends with close.
+func enclosed(line, open, close string) bool {
+	return len(line) >= len(open)+len(close) && strings.HasPrefix(line, open) && strings.HasSuffix(line, close)
+}
+
+// joinBrackets joins the [[scene descriptions]] and {{title texts}} that span several lines.
+func joinBrackets(lines []string) []string {
+	var result []string
+
+	for i := 0; i < len(lines); i++ {
+		line := strings.TrimSpace(lines[i])
+
+		for _, pair := range [][2]string{{"[[", "]]"}, {"{{", "}}"}} {
+			if !strings.HasPrefix(line, pair[0]) || strings.Contains(line, pair[1]) {
+				continue
+			}
+
+			for j := i + 1; j < len(lines); j++ {
+				if strings.Contains(lines[j], pair[1]) {
+					line = line + " " + strings.TrimSpace(strings.Join(lines[i+1:j+1], " "))
+					i = j
+					break
+				}
+			}
+		}
+
+		result = append(result, line)
+	}
+
+	return result
+}
@@ -0,0 +1,84 @@
+package comic
+
+import (
+	"reflect"
+	"testing"
+)
+
+const rawTranscript = `[[Megan and Cueball are standing.]]
+Megan: Did you see this?
+Cueball (off-panel): See what?
+
+[[Megan holds up a phone
+showing a chart.]]
+((The chart is unreadable.))
+http://example.com
+Megan: This!
+[[Cueball stares.]]
+cueball: Huh.
+{{Title text: It was a very good chart.}}`
+
+func TestParseTranscript(t *testing.T) {
+	got := ParseTranscript(rawTranscript)
+
+	want := Transcript{
+		Panels: []Panel{
+			{Lines: []TranscriptLine{
+				{SceneLine, "", "Megan and Cueball are standing."},
+				{DialogueLine, "Megan", "Did you see this?"},
+				{DialogueLine, "Cueball (off-panel)", "See what?"},
+			}},
+			{Lines: []TranscriptLine{
+				{SceneLine, "", "Megan holds up a phone showing a chart."},
+				{NoteLine, "", "The chart is unreadable."},
+				{TextLine, "", "http://example.com"},
+				{DialogueLine, "Megan", "This!"},
+			}},
+			{Lines: []TranscriptLine{
+				{SceneLine, "", "Cueball stares."},
+				{DialogueLine, "cueball", "Huh."},
+			}},
+		},
+		TitleText: "It was a very good chart.",
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %+v, got %+v", want, got)
+	}
+
+	if speakers := got.Speakers(); !reflect.DeepEqual(speakers, []string{"Megan", "Cueball"}) {
+		t.Errorf("expected speakers Megan and Cueball, got %v", speakers)
+	}
+}
+
+func TestTranscriptString(t *testing.T) {
+	want := "[Megan and Cueball are standing.]\nMegan: Did you see this?\nCueball (off-panel): See what?\n"
+
+	if got := ParseTranscript(rawTranscript).String(); got[:len(want)] != want {
+		t.Errorf("expected the first panel %q, got %q", want, got)
+	}
+}
+
+func TestSpeakerFilter(t *testing.T) {
+	var c Comics
+
+	c.Load([]XKCD{
+		{Number: 1, Transcript: rawTranscript},
+		{Number: 2, Transcript: "Black Hat: Megan is here.\n[[Megan waves.]]"},
+		{Number: 3, Transcript: "Beret Guy: Hello."},
+	})
+
+	tests := map[string][]int{"megan": {1}, "Cueball": {1}, "Black Hat": {2}, "Sign": nil}
+
+	for name, want := range tests {
+		var got []int
+
+		for _, xkcd := range c.Query().Filter(SpeakerFilter(name)).All() {
+			got = append(got, xkcd.Number)
+		}
+
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("%s: expected %v, got %v", name, want, got)
+		}
+	}
+}
@@ -1,10 +1,12 @@
 package comic
 
 import (
+	"errors"
 	"fmt"
 	"xkcd2/config"
 	"xkcd2/tools/imaging"
 	"xkcd2/tools/logger"
+	"xkcd2/webclient"
 )
 
 // XKCD struct that stores the data returned from the JSON document from the xkcd website.
@@ -23,6 +25,28 @@ type XKCD struct {
 
 	// base64 encoded image downloaded from ImageURL
 	Image string
+
+	// size in bytes and hex encoded SHA-256 checksum of the image, recorded when it was downloaded
+	ImageSize int
+	ImageHash string
+
+	// perceptual hashes of the image, used to find visually similar comics
+	Hashes imaging.Hashes
+
+	// dimensions, format, frames and colorfulness of the image
+	Metadata imaging.Metadata
+
+	// variants of the image and other files of the comic, see Asset
+	Assets []Asset
+
+	// values of the text fields as received from the web site, for the fields changed by Normalize
+	Original map[string]string `json:"-"`
+
+	// JSON document as received from the web site, including the keys without a field, see Extra
+	Payload []byte `json:"-"`
+
+	// cache validators of the JSON response, used to check the comic for updates cheaply
+	Validators webclient.Validators `json:"-"`
 }
 
 // Download fetches the JSON contents of the XKCD comic based on its number. If number is 0 it will
@@ -49,14 +73,136 @@ func (xkcd *XKCD) Download(comicNumber int) error {
 	return err
 }
 
+// CheckUpdate downloads the JSON of the comic again using a conditional request with the stored Validators.
+// It returns nil if the comic has not changed on the web site, otherwise it returns the downloaded comic.
+// The receiver is not changed, add the result to the collection to merge it with the stored comic.
+func (xkcd *XKCD) CheckUpdate() (*XKCD, error) {
+	defer logger.Trace(fmt.Sprintf("func CheckUpdate(%d)", xkcd.Number))()
+
+	url := fmt.Sprintf("%s/%d/%s", config.HomeURL, xkcd.Number, config.JSONURL)
+	result, err := fetchConditional(url, xkcd.Validators)
+
+	if err == webclient.ErrNotModified {
+		return nil, nil
+	}
+
+	return result, err
+}
+
+// ErrNoImage is returned by VerifyImage for a comic without a stored image.
+var ErrNoImage = errors.New("no image stored")
+
 // DownloadImage fetches an XKCD image from imageURL.
 // NOTE: There are some comics whose image cannot be retrieved. It would require that we parse the HTML.
 // For that reason the error is ignored, but in any case, XKCD struct is returned while Image is left as empty string.
+// An image that cannot be decoded, such as a truncated download, is not stored either.
 func (xkcd *XKCD) DownloadImage(imageURL string) error {
 	imageByte, err := downloadImage(imageURL)
 
-	if err == nil {
-		xkcd.Image = imaging.EncodeToBase64(imageByte)
+	if err != nil {
+		return nil
+	}
+
+	img, format, err := imaging.Decode(imageByte)
+
+	if err != nil {
+		logger.Info(fmt.Sprintf("DownloadImage(%s): %v", imageURL, err))
+		return nil
+	}
+
+	metadata, err := imaging.Describe(img, format, imageByte)
+
+	if err != nil {
+		logger.Info(fmt.Sprintf("DownloadImage(%s): %v", imageURL, err))
+		return nil
+	}
+
+	xkcd.Image = imaging.EncodeToBase64(imageByte)
+	xkcd.ImageSize = len(imageByte)
+	xkcd.ImageHash = imaging.Hash(imageByte)
+	xkcd.Hashes = imaging.ComputeHashes(img)
+	xkcd.Metadata = metadata
+
+	return nil
+}
+
+// ImageData returns the stored image decoded from Base-64, or ErrNoImage if there is none.
+func (xkcd *XKCD) ImageData() ([]byte, error) {
+	if xkcd.Image == "" {
+		return nil, ErrNoImage
+	}
+
+	data, err := imaging.DecodeFromBase64(xkcd.Image)
+
+	if err != nil {
+		return nil, fmt.Errorf("image data: %v", err)
+	}
+
+	return data, nil
+}
+
+// ComputeHashes computes the perceptual hashes of the stored image, for the images downloaded before
+// the hashes were recorded.
+func (xkcd *XKCD) ComputeHashes() error {
+	data, err := xkcd.ImageData()
+
+	if err != nil {
+		return err
+	}
+
+	img, _, err := imaging.Decode(data)
+
+	if err != nil {
+		return err
+	}
+
+	xkcd.Hashes = imaging.ComputeHashes(img)
+
+	return nil
+}
+
+// Analyze records the metadata of the stored image, for the images downloaded before the metadata was recorded.
+func (xkcd *XKCD) Analyze() error {
+	data, err := xkcd.ImageData()
+
+	if err != nil {
+		return err
+	}
+
+	metadata, err := imaging.Analyze(data)
+
+	if err != nil {
+		return err
+	}
+
+	xkcd.Metadata = metadata
+
+	return nil
+}
+
+// VerifyImage checks that the stored image is a valid image and that it matches the size and the checksum
+// recorded when it was downloaded. Images stored before the checksums were recorded are only decoded.
+func (xkcd *XKCD) VerifyImage() error {
+	data, err := xkcd.ImageData()
+
+	if err == ErrNoImage {
+		return err
+	}
+
+	if err != nil {
+		return fmt.Errorf("verify image: %v", err)
+	}
+
+	if xkcd.ImageSize != 0 && xkcd.ImageSize != len(data) {
+		return fmt.Errorf("verify image: size %d, expected %d", len(data), xkcd.ImageSize)
+	}
+
+	if xkcd.ImageHash != "" && xkcd.ImageHash != imaging.Hash(data) {
+		return fmt.Errorf("verify image: checksum mismatch")
+	}
+
+	if _, err := imaging.Validate(data); err != nil {
+		return fmt.Errorf("verify image: %v", err)
 	}
 
 	return nil
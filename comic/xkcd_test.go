@@ -3,6 +3,8 @@ package comic
 import (
 	"bytes"
 	"fmt"
+	"image"
+	"image/png"
 	"io/ioutil"
 	"net/http"
 	"testing"
@@ -99,3 +101,86 @@ func TestDownloadComicLatest(t *testing.T) {
 		t.Errorf("expected xkcd.Number to be 1, got %d", xkcd.Number)
 	}
 }
+
+func TestCheckUpdate(t *testing.T) {
+	saved := webclient.Client
+	defer func() { webclient.Client = saved }()
+
+	webclient.Client = &mocks.MockClient{}
+	mocks.GetDoFunc = func(req *http.Request) (*http.Response, error) {
+		if req.Header.Get("If-None-Match") == `"v2"` {
+			return &http.Response{StatusCode: http.StatusNotModified, Body: ioutil.NopCloser(bytes.NewReader(nil))}, nil
+		}
+
+		header := http.Header{}
+		header.Set("ETag", `"v2"`)
+
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     header,
+			Body:       ioutil.NopCloser(bytes.NewReader([]byte(`{"num": 1, "title": "corrected"}`))),
+		}, nil
+	}
+
+	stored := &XKCD{Number: 1, Title: "title", Validators: webclient.Validators{ETag: `"v1"`}}
+
+	got, err := stored.CheckUpdate()
+
+	if err != nil || got == nil || got.Title != "corrected" || got.Validators.ETag != `"v2"` {
+		t.Fatalf("expected the updated comic with new validators, got %+v, %v", got, err)
+	}
+
+	got, err = got.CheckUpdate()
+
+	if err != nil || got != nil {
+		t.Errorf("expected nil for a comic that has not changed, got %+v, %v", got, err)
+	}
+}
+
+func TestDownloadAndVerifyImage(t *testing.T) {
+	saved := webclient.Client
+	defer func() { webclient.Client = saved }()
+
+	var buf bytes.Buffer
+
+	if err := png.Encode(&buf, image.NewGray(image.Rect(0, 0, 8, 8))); err != nil {
+		t.Fatal(err)
+	}
+
+	body := buf.Bytes()
+
+	webclient.Client = &mocks.MockClient{}
+	mocks.GetDoFunc = func(req *http.Request) (*http.Response, error) {
+		return &http.Response{StatusCode: http.StatusOK, Body: ioutil.NopCloser(bytes.NewReader(body))}, nil
+	}
+
+	xkcd := &XKCD{Number: 1}
+	xkcd.DownloadImage("http://localhost/1/image.png")
+
+	if xkcd.ImageSize != len(body) || xkcd.ImageHash == "" {
+		t.Fatalf("expected the image size and checksum to be recorded, got %d, %q", xkcd.ImageSize, xkcd.ImageHash)
+	}
+
+	if err := xkcd.VerifyImage(); err != nil {
+		t.Errorf("expected the image to verify, got %v", err)
+	}
+
+	xkcd.ImageHash = "tampered"
+
+	if err := xkcd.VerifyImage(); err == nil {
+		t.Errorf("expected a checksum mismatch")
+	}
+
+	// truncated download
+	body = body[:len(body)/2]
+	truncated := &XKCD{Number: 2}
+	truncated.DownloadImage("http://localhost/2/image.png")
+
+	if truncated.Image != "" {
+		t.Errorf("expected a truncated image not to be stored")
+	}
+
+	if err := truncated.VerifyImage(); err != ErrNoImage {
+		t.Errorf("expected ErrNoImage, got %v", err)
+	}
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+
+	"xkcd2/config"
+	"xkcd2/tools/logger"
+)
+
+// command is an action that can be run against the offline collection instead of the default sync.
+// Each command parses its own arguments, which follow the command name on the command line.
+type command struct {
+	usage string
+	help  string
+	run   func(args []string) error
+}
+
+// commands holds every registered command by its name. Commands register themselves in init.
+var commands = map[string]*command{}
+
+// runCommand looks up the command by name and runs it with the remaining arguments.
+func runCommand(name string, args []string) {
+	defer logger.Trace(fmt.Sprintf("runCommand(%s)", name))()
+
+	cmd, ok := commands[name]
+
+	if !ok {
+		fmt.Fprintf(flag.CommandLine.Output(), "unknown command: %s\n\n", name)
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	if err := cmd.run(args); err != nil {
+		log.Fatalf("%s: %v", name, err)
+	}
+}
+
+// usage prints the global flags followed by the list of available commands.
+func usage() {
+	out := flag.CommandLine.Output()
+
+	fmt.Fprintf(out, "%s\n\nUsage: %s [flags] [command [arguments]]\n\n", config.AppTitle, os.Args[0])
+	fmt.Fprintf(out, "Without a command the offline index is synced with the xkcd web site.\n\nFlags:\n")
+	flag.PrintDefaults()
+
+	names := make([]string, 0, len(commands))
+
+	for name := range commands {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	fmt.Fprintf(out, "\nCommands:\n")
+
+	for _, name := range names {
+		fmt.Fprintf(out, "  %-30s %s\n", commands[name].usage, commands[name].help)
+	}
+}
+
+// newFlagSet creates a flag set for a command that prints the command usage on error.
+func newFlagSet(name string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ExitOnError)
+
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: %s %s\n\n%s\n", os.Args[0], commands[name].usage, commands[name].help)
+		fs.PrintDefaults()
+	}
+
+	return fs
+}
@@ -0,0 +1,16 @@
+package config
+
+// AbsentComics are the comic numbers that were never published, so they are never downloaded
+// nor reported as missing. There is no comic 404, the page returns "404 Not Found" on purpose.
+var AbsentComics = []int{404}
+
+// IsAbsent returns true if comicNum is one of AbsentComics.
+func IsAbsent(comicNum int) bool {
+	for _, num := range AbsentComics {
+		if num == comicNum {
+			return true
+		}
+	}
+
+	return false
+}
@@ -3,3 +3,25 @@ package config
 const AppTitle string = "XKCD syncing utility v2.0"
 const LogFileName string = "xkcd.log"
 const IndexFile string = "xkcd.idx"
+const HistoryFile string = "xkcd.history"
+const SyncFile string = "xkcd.sync"
+const SiteFolder string = "site"
+const AtomFile string = "atom.xml"
+const RSSFile string = "rss.xml"
+const ThumbsFolder string = "thumbs"
+const BlobsFolder string = "blobs"
+const AnnotationsFile string = "annotations.json"
+const ListsFolder string = "lists"
+
+// FeedSize is the default number of comics written to a feed
+const FeedSize int = 20
+
+// ThumbSize is the default size in pixels of the longer side of a thumbnail
+const ThumbSize int = 150
+
+// MaxThumbSize is the largest thumbnail size that can be requested
+const MaxThumbSize int = 1024
+
+// ThumbSizes are the sizes the thumbnails are generated in, ordered from the smallest to MaxThumbSize.
+// Other sizes are rounded up to one of them, so the cache holds a few thumbnails per comic at most.
+var ThumbSizes = []int{75, ThumbSize, 300, 600, MaxThumbSize}
@@ -0,0 +1,127 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"sort"
+
+	"xkcd2/comic"
+	"xkcd2/persistence"
+	"xkcd2/tools/imaging"
+)
+
+// downloadResult is the outcome of downloading a single comic. xkcd is nil if err is not nil.
+type downloadResult struct {
+	comicNum int
+	xkcd     *comic.XKCD
+	err      error
+}
+
+// downloadComics downloads the comics concurrently and returns the outcome ordered by the comic number.
+// The comics are not added to the collection.
+func downloadComics(numbers []int) []downloadResult {
+	resultChan := make(chan downloadResult)
+
+	// counting semaphore that limits the number of concurrent downloads, the same as in fetchComics
+	semaphore := make(chan struct{}, 20)
+
+	for _, num := range numbers {
+		go func(comicNum int) {
+			semaphore <- struct{}{}
+			defer func() { <-semaphore }()
+
+			xkcd := &comic.XKCD{}
+
+			if err := xkcd.Download(comicNum); err != nil {
+				resultChan <- downloadResult{comicNum, nil, err}
+				return
+			}
+
+			resultChan <- downloadResult{comicNum, xkcd, nil}
+		}(num)
+	}
+
+	results := make([]downloadResult, 0, len(numbers))
+
+	for range numbers {
+		results = append(results, <-resultChan)
+	}
+
+	sort.Slice(results, func(i, j int) bool { return results[i].comicNum < results[j].comicNum })
+
+	return results
+}
+
+// latestComicNum returns the number of the latest comic on the xkcd web site.
+func latestComicNum() (int, error) {
+	latest := &comic.XKCD{}
+
+	if err := latest.Download(0); err != nil {
+		return 0, err
+	}
+
+	return latest.Number, nil
+}
+
+// downloadImages downloads the images of the stored comics concurrently and returns the outcome ordered
+// by the comic number. The xkcd of a result is a copy of the stored comic with the new image, which is
+// verified before it is returned, and with the image variants found, see comic.DiscoverAssets.
+// The collection is not changed, use storeImage to apply the result.
+func downloadImages(numbers []int) []downloadResult {
+	resultChan := make(chan downloadResult)
+	semaphore := make(chan struct{}, 20)
+
+	for _, num := range numbers {
+		go func(comicNum int) {
+			semaphore <- struct{}{}
+			defer func() { <-semaphore }()
+
+			_, xkcd := comics.Get(comicNum)
+
+			if xkcd == nil {
+				resultChan <- downloadResult{comicNum, nil, fmt.Errorf("comic %d not found", comicNum)}
+				return
+			}
+
+			xkcd.Image, xkcd.ImageSize, xkcd.ImageHash = "", 0, ""
+			xkcd.Hashes, xkcd.Metadata = imaging.Hashes{}, imaging.Metadata{}
+			xkcd.DownloadImage(xkcd.ImageURL)
+
+			if err := xkcd.VerifyImage(); err != nil {
+				resultChan <- downloadResult{comicNum, nil, err}
+				return
+			}
+
+			if err := xkcd.DownloadAssets(persistence.WriteBlob); err != nil {
+				resultChan <- downloadResult{comicNum, nil, err}
+				return
+			}
+
+			resultChan <- downloadResult{comicNum, xkcd, nil}
+		}(num)
+	}
+
+	results := make([]downloadResult, 0, len(numbers))
+
+	for range numbers {
+		results = append(results, <-resultChan)
+	}
+
+	sort.Slice(results, func(i, j int) bool { return results[i].comicNum < results[j].comicNum })
+
+	return results
+}
+
+// storeImage copies the image of a successful downloadImages result to the stored comic. The cached
+// thumbnails of the old image are removed.
+func storeImage(result downloadResult) {
+	comics.Update(result.comicNum, func(xkcd *comic.XKCD) {
+		xkcd.Image, xkcd.ImageSize, xkcd.ImageHash = result.xkcd.Image, result.xkcd.ImageSize, result.xkcd.ImageHash
+		xkcd.Hashes, xkcd.Metadata = result.xkcd.Hashes, result.xkcd.Metadata
+		xkcd.Assets = result.xkcd.Assets
+	})
+
+	if err := thumbCache().Invalidate(result.comicNum); err != nil {
+		log.Println(err)
+	}
+}
@@ -0,0 +1,243 @@
+// Package export writes a collection of XKCD comics in formats meant for other programs: a JSON array,
+// JSON Lines or CSV. The JSON formats can also carry the original JSON documents of the web site verbatim.
+package export
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"xkcd2/comic"
+	"xkcd2/tools/logger"
+)
+
+// Formats of the export.
+const (
+	JSON      = "json"
+	JSONLines = "jsonl"
+	CSV       = "csv"
+)
+
+// Options change the content of the export.
+type Options struct {
+	Format string // JSON, JSONLines or CSV, the default is JSON
+
+	// Raw writes the JSON documents as received from the web site instead of the records. For the comics
+	// downloaded before the documents were stored, the document is rebuilt from the record.
+	Raw bool
+
+	// Asset is the role of the comic asset whose URL is exported as the image, see comic.Asset.
+	// The comics without an asset of the role export ImageURL.
+	Asset string
+}
+
+// Record is a comic as written by the export. The transcript is rendered from the parsed transcript.
+type Record struct {
+	Number     int                        `json:"num"`
+	Date       string                     `json:"date,omitempty"`
+	Title      string                     `json:"title"`
+	SafeTitle  string                     `json:"safe_title"`
+	ImageURL   string                     `json:"img"`
+	ImageAlt   string                     `json:"alt"`
+	Transcript string                     `json:"transcript,omitempty"`
+	Speakers   []string                   `json:"speakers,omitempty"`
+	Link       string                     `json:"link,omitempty"`
+	News       string                     `json:"news,omitempty"`
+	Extra      map[string]json.RawMessage `json:"extra,omitempty"`
+}
+
+// NewRecord builds the exported record of xkcd.
+func NewRecord(xkcd *comic.XKCD, options Options) Record {
+	transcript := xkcd.ParsedTranscript()
+
+	result := Record{
+		Number:     xkcd.Number,
+		Title:      xkcd.Title,
+		SafeTitle:  xkcd.SafeTitle,
+		ImageURL:   xkcd.ImageURL,
+		ImageAlt:   xkcd.ImageAlt,
+		Transcript: strings.TrimSpace(transcript.String()),
+		Speakers:   transcript.Speakers(),
+		Link:       xkcd.Link,
+		News:       xkcd.News,
+	}
+
+	if date, err := xkcd.Date(); err == nil {
+		result.Date = date.Format(comic.DateLayout)
+	}
+
+	if asset, ok := xkcd.Asset(options.Asset); ok && asset.URL != "" {
+		result.ImageURL = asset.URL
+	}
+
+	if extra, err := xkcd.Extra(); err == nil {
+		result.Extra = extra
+	} else {
+		logger.Info(fmt.Sprintf("export %d: %v", xkcd.Number, err))
+	}
+
+	return result
+}
+
+// Write writes comics to w in the format of the options.
+func Write(w io.Writer, comics []comic.XKCD, options Options) error {
+	defer logger.Trace("func export.Write")()
+
+	switch options.Format {
+	case JSON, "":
+		return writeJSON(w, comics, options)
+	case JSONLines:
+		return writeJSONLines(w, comics, options)
+	case CSV:
+		if options.Raw {
+			return fmt.Errorf("export: the raw documents can be exported only as JSON")
+		}
+
+		return writeCSV(w, comics, options)
+	}
+
+	return fmt.Errorf("export: unknown format %q", options.Format)
+}
+
+// upstream is the JSON document of the web site rebuilt from a record, in the order of the web site.
+type upstream struct {
+	Month      string `json:"month"`
+	Number     int    `json:"num"`
+	Link       string `json:"link"`
+	Year       string `json:"year"`
+	News       string `json:"news"`
+	SafeTitle  string `json:"safe_title"`
+	Transcript string `json:"transcript"`
+	ImageAlt   string `json:"alt"`
+	ImageURL   string `json:"img"`
+	Title      string `json:"title"`
+	Day        string `json:"day"`
+}
+
+// document returns the JSON of a single comic. With Raw, it is the original document unchanged, or
+// compacted to a single line if compact is true.
+func document(xkcd *comic.XKCD, options Options, compact bool) ([]byte, error) {
+	if !options.Raw {
+		return marshal(NewRecord(xkcd, options))
+	}
+
+	if len(xkcd.Payload) == 0 {
+		// the original values of the normalized fields are the closest to the document
+		original := func(field, value string) string {
+			if raw, ok := xkcd.Original[field]; ok {
+				return raw
+			}
+
+			return value
+		}
+
+		doc := upstream{xkcd.Month, xkcd.Number, xkcd.Link, xkcd.Year, xkcd.News, original("SafeTitle", xkcd.SafeTitle),
+			original("Transcript", xkcd.Transcript), original("ImageAlt", xkcd.ImageAlt), xkcd.ImageURL,
+			original("Title", xkcd.Title), xkcd.Day}
+
+		return marshal(doc)
+	}
+
+	if !json.Valid(xkcd.Payload) {
+		return nil, fmt.Errorf("export %d: invalid JSON document", xkcd.Number)
+	}
+
+	if !compact {
+		return bytes.TrimSpace(xkcd.Payload), nil
+	}
+
+	var buf bytes.Buffer
+
+	if err := json.Compact(&buf, xkcd.Payload); err != nil {
+		return nil, fmt.Errorf("export %d: %v", xkcd.Number, err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+// marshal returns the JSON encoding of v without escaping the HTML characters, which are common in the
+// transcripts and the extra parts.
+func marshal(v interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+
+	if err := encoder.Encode(v); err != nil {
+		return nil, fmt.Errorf("export: %v", err)
+	}
+
+	return bytes.TrimSpace(buf.Bytes()), nil
+}
+
+// writeJSON writes the comics as a JSON array.
+func writeJSON(w io.Writer, comics []comic.XKCD, options Options) error {
+	if _, err := io.WriteString(w, "["); err != nil {
+		return err
+	}
+
+	for i := range comics {
+		data, err := document(&comics[i], options, false)
+
+		if err != nil {
+			return err
+		}
+
+		separator := ",\n"
+
+		if i == 0 {
+			separator = "\n"
+		}
+
+		if _, err := io.WriteString(w, separator+string(data)); err != nil {
+			return err
+		}
+	}
+
+	_, err := io.WriteString(w, "\n]\n")
+
+	return err
+}
+
+// writeJSONLines writes a JSON document per line.
+func writeJSONLines(w io.Writer, comics []comic.XKCD, options Options) error {
+	for i := range comics {
+		data, err := document(&comics[i], options, true)
+
+		if err != nil {
+			return err
+		}
+
+		if _, err := w.Write(append(data, '\n')); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// writeCSV writes the main fields of the comics with a header row.
+func writeCSV(w io.Writer, comics []comic.XKCD, options Options) error {
+	writer := csv.NewWriter(w)
+
+	if err := writer.Write([]string{"num", "date", "title", "img", "alt", "speakers"}); err != nil {
+		return err
+	}
+
+	for i := range comics {
+		r := NewRecord(&comics[i], options)
+		row := []string{strconv.Itoa(r.Number), r.Date, r.Title, r.ImageURL, r.ImageAlt, strings.Join(r.Speakers, ";")}
+
+		if err := writer.Write(row); err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+
+	return writer.Error()
+}
@@ -0,0 +1,96 @@
+package export
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"xkcd2/comic"
+)
+
+const payload = `{"month": "4", "num": 1350, "title": "Lorenz", "extra_parts": {"pre": "<div>"}, "img": "https://imgs.xkcd.com/comics/lorenz.png", "day": "1", "year": "2014"}`
+
+func setupComics() []comic.XKCD {
+	return []comic.XKCD{
+		{Number: 1, Title: "Tom & Jerry", Year: "2006", Month: "1", Day: "1", ImageURL: "https://imgs.xkcd.com/comics/a.png",
+			Transcript: "[[A man.]]\nMan: Hello.", Original: map[string]string{"Title": "Tom &amp; Jerry"},
+			Assets: []comic.Asset{{Role: comic.Asset2x, URL: "https://imgs.xkcd.com/comics/a_2x.png"}}},
+		{Number: 1350, Title: "Lorenz", Year: "2014", Month: "4", Day: "1", ImageURL: "https://imgs.xkcd.com/comics/lorenz.png",
+			Payload: []byte(payload)},
+	}
+}
+
+func TestWriteJSON(t *testing.T) {
+	var buf bytes.Buffer
+
+	if err := Write(&buf, setupComics(), Options{Asset: comic.Asset2x}); err != nil {
+		t.Fatal(err)
+	}
+
+	var records []Record
+
+	if err := json.Unmarshal(buf.Bytes(), &records); err != nil {
+		t.Fatalf("expected a JSON array, got %v: %s", err, buf.String())
+	}
+
+	first, second := records[0], records[1]
+
+	if first.Date != "2006-01-01" || first.ImageURL != "https://imgs.xkcd.com/comics/a_2x.png" || first.Transcript != "[A man.]\nMan: Hello." {
+		t.Errorf("unexpected record %+v", first)
+	}
+
+	if len(first.Speakers) != 1 || first.Speakers[0] != "Man" {
+		t.Errorf("expected speaker Man, got %v", first.Speakers)
+	}
+
+	if second.ImageURL != "https://imgs.xkcd.com/comics/lorenz.png" || string(second.Extra["extra_parts"]) != `{"pre":"<div>"}` {
+		t.Errorf("expected the image URL and the extra parts, got %+v", second)
+	}
+}
+
+func TestWriteRaw(t *testing.T) {
+	var buf bytes.Buffer
+
+	if err := Write(&buf, setupComics(), Options{Raw: true}); err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.Contains(buf.String(), payload) {
+		t.Errorf("expected the payload verbatim, got %s", buf.String())
+	}
+
+	if !strings.Contains(buf.String(), `"title":"Tom &amp; Jerry"`) {
+		t.Errorf("expected the rebuilt document with the original title, got %s", buf.String())
+	}
+
+	buf.Reset()
+
+	if err := Write(&buf, setupComics(), Options{Format: JSONLines, Raw: true}); err != nil {
+		t.Fatal(err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+
+	if len(lines) != 2 || !json.Valid([]byte(lines[1])) || !strings.Contains(lines[1], `"extra_parts":{"pre":"<div>"}`) {
+		t.Errorf("expected a compacted document per line, got %q", lines)
+	}
+
+	if err := Write(&buf, setupComics(), Options{Format: CSV, Raw: true}); err == nil {
+		t.Errorf("expected an error for raw CSV")
+	}
+}
+
+func TestWriteCSV(t *testing.T) {
+	var buf bytes.Buffer
+
+	if err := Write(&buf, setupComics(), Options{Format: CSV}); err != nil {
+		t.Fatal(err)
+	}
+
+	want := "num,date,title,img,alt,speakers\n1,2006-01-01,Tom & Jerry,https://imgs.xkcd.com/comics/a.png,,Man\n"
+
+	if !strings.HasPrefix(buf.String(), want) {
+		t.Errorf("expected %q, got %q", want, buf.String())
+	}
+}
@@ -0,0 +1,256 @@
+// Package feed generates Atom and RSS 2.0 feeds of the latest comics in a collection.
+package feed
+
+import (
+	"encoding/xml"
+	"fmt"
+	"html/template"
+	"os"
+	"strings"
+	"time"
+
+	"xkcd2/comic"
+	"xkcd2/config"
+	"xkcd2/tools/logger"
+)
+
+// Supported feed formats
+const (
+	Atom = "atom"
+	RSS  = "rss"
+)
+
+const feedTitle = "xkcd.com"
+const feedDescription = "xkcd: A webcomic of romance, sarcasm, math, and language."
+const feedAuthor = "Randall Munroe"
+
+type atomFeed struct {
+	XMLName xml.Name    `xml:"http://www.w3.org/2005/Atom feed"`
+	Title   string      `xml:"title"`
+	ID      string      `xml:"id"`
+	Links   []atomLink  `xml:"link"`
+	Author  atomAuthor  `xml:"author"`
+	Updated string      `xml:"updated"`
+	Entries []atomEntry `xml:"entry"`
+}
+
+type atomAuthor struct {
+	Name string `xml:"name"`
+}
+
+type atomLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr,omitempty"`
+}
+
+type atomEntry struct {
+	Title   string      `xml:"title"`
+	ID      string      `xml:"id"`
+	Link    atomLink    `xml:"link"`
+	Updated string      `xml:"updated"`
+	Summary string      `xml:"summary"`
+	Content atomContent `xml:"content"`
+}
+
+type atomContent struct {
+	Type string `xml:"type,attr"`
+	Body string `xml:",chardata"`
+}
+
+type rssFeed struct {
+	XMLName xml.Name   `xml:"rss"`
+	Version string     `xml:"version,attr"`
+	Channel rssChannel `xml:"channel"`
+}
+
+type rssChannel struct {
+	Title         string    `xml:"title"`
+	Link          string    `xml:"link"`
+	Description   string    `xml:"description"`
+	LastBuildDate string    `xml:"lastBuildDate,omitempty"`
+	Items         []rssItem `xml:"item"`
+}
+
+type rssItem struct {
+	Title       string `xml:"title"`
+	Link        string `xml:"link"`
+	Description string `xml:"description"`
+	GUID        string `xml:"guid"`
+	PubDate     string `xml:"pubDate,omitempty"`
+}
+
+var contentTemplate = template.Must(template.New("content").Parse(
+	`<img src="{{.ImageURL}}" title="{{.ImageAlt}}" alt="{{.Title}}"><p>{{.ImageAlt}}</p>`))
+
+// Generate returns the feed document in the given format (Atom or RSS) with the latest count comics.
+// If count is 0 or less, all the comics are included. selfURL is the address the feed is published at,
+// it is added to the Atom feed as the self link unless it is empty.
+func Generate(format string, comics []comic.XKCD, count int, selfURL string) ([]byte, error) {
+	defer logger.Trace(fmt.Sprintf("func Generate(%s, %d)", format, count))()
+
+	latest := latestComics(comics, count)
+
+	var doc interface{}
+	var err error
+
+	switch format {
+	case Atom:
+		doc, err = atom(latest, selfURL)
+	case RSS:
+		doc, err = rss(latest)
+	default:
+		return nil, fmt.Errorf("feed: unknown format %q", format)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := xml.MarshalIndent(doc, "", "  ")
+
+	if err != nil {
+		return nil, fmt.Errorf("feed marshal: %v", err)
+	}
+
+	return append([]byte(xml.Header), result...), nil
+}
+
+// Write generates the feed and writes it into filename. See Generate for selfURL.
+func Write(filename string, format string, comics []comic.XKCD, count int, selfURL string) error {
+	data, err := Generate(format, comics, count, selfURL)
+
+	if err != nil {
+		return err
+	}
+
+	if err := os.WriteFile(filename, data, 0644); err != nil {
+		return fmt.Errorf("feed write: %v", err)
+	}
+
+	return nil
+}
+
+// ComicURL returns the address of the comic on the xkcd web site.
+func ComicURL(comicNum int) string {
+	return fmt.Sprintf("%s/%d/", config.HomeURL, comicNum)
+}
+
+// atom creates the Atom feed. The feed is updated at the latest valid publication date, or at the time
+// of generation if no comic has a valid date. Entries with a malformed date use the feed update time.
+func atom(comics []comic.XKCD, selfURL string) (*atomFeed, error) {
+	result := &atomFeed{
+		Title:  feedTitle,
+		ID:     config.HomeURL + "/",
+		Links:  []atomLink{{Href: config.HomeURL + "/", Rel: "alternate"}},
+		Author: atomAuthor{Name: feedAuthor},
+	}
+
+	if selfURL != "" {
+		result.Links = append(result.Links, atomLink{Href: selfURL, Rel: "self"})
+	}
+
+	feedUpdated := time.Now().UTC()
+	found := false
+
+	for _, xkcd := range comics {
+		if date, ok := published(xkcd); ok && (!found || date.After(feedUpdated)) {
+			feedUpdated = date
+			found = true
+		}
+	}
+
+	result.Updated = feedUpdated.Format(time.RFC3339)
+
+	for _, xkcd := range comics {
+		content, err := renderContent(xkcd)
+
+		if err != nil {
+			return nil, err
+		}
+
+		updated := result.Updated
+
+		if date, ok := published(xkcd); ok {
+			updated = date.Format(time.RFC3339)
+		}
+
+		result.Entries = append(result.Entries, atomEntry{
+			Title:   xkcd.Title,
+			ID:      ComicURL(xkcd.Number),
+			Link:    atomLink{Href: ComicURL(xkcd.Number), Rel: "alternate"},
+			Updated: updated,
+			Summary: xkcd.ImageAlt,
+			Content: atomContent{Type: "html", Body: content},
+		})
+	}
+
+	return result, nil
+}
+
+func rss(comics []comic.XKCD) (*rssFeed, error) {
+	result := &rssFeed{
+		Version: "2.0",
+		Channel: rssChannel{
+			Title:       feedTitle,
+			Link:        config.HomeURL + "/",
+			Description: feedDescription,
+		},
+	}
+
+	for _, xkcd := range comics {
+		content, err := renderContent(xkcd)
+
+		if err != nil {
+			return nil, err
+		}
+
+		// pubDate is optional in RSS, so it is left out when the date is malformed
+		pubDate := ""
+
+		if date, ok := published(xkcd); ok {
+			pubDate = date.Format(time.RFC1123Z)
+		}
+
+		if result.Channel.LastBuildDate == "" {
+			result.Channel.LastBuildDate = pubDate
+		}
+
+		result.Channel.Items = append(result.Channel.Items, rssItem{
+			Title:       xkcd.Title,
+			Link:        ComicURL(xkcd.Number),
+			Description: content,
+			GUID:        ComicURL(xkcd.Number),
+			PubDate:     pubDate,
+		})
+	}
+
+	return result, nil
+}
+
+// latestComics returns count comics with the highest numbers, the latest first.
+func latestComics(comics []comic.XKCD, count int) []comic.XKCD {
+	return comic.NewQuery(comics).OrderBy(comic.OrderByNumber, true).Limit(count).All()
+}
+
+// renderContent returns the HTML body of a feed entry with the embedded comic image and its alt text.
+func renderContent(xkcd comic.XKCD) (string, error) {
+	var sb strings.Builder
+
+	if err := contentTemplate.Execute(&sb, xkcd); err != nil {
+		return "", fmt.Errorf("feed content %d: %v", xkcd.Number, err)
+	}
+
+	return sb.String(), nil
+}
+
+// published returns the publication date of xkcd and false if the date is malformed.
+func published(xkcd comic.XKCD) (time.Time, bool) {
+	result, err := xkcd.Date()
+
+	if err != nil {
+		logger.Info(fmt.Sprintf("feed: %v", err))
+		return time.Time{}, false
+	}
+
+	return result, true
+}
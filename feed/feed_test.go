@@ -0,0 +1,125 @@
+package feed
+
+import (
+	"encoding/xml"
+	"strings"
+	"testing"
+
+	"xkcd2/comic"
+)
+
+func setupComics() []comic.XKCD {
+	return []comic.XKCD{
+		{Number: 1, Title: "First", Year: "2006", Month: "1", Day: "1", ImageAlt: "alt 1", ImageURL: "https://imgs.xkcd.com/comics/1.png"},
+		{Number: 3, Title: "Third", Year: "2006", Month: "1", Day: "3", ImageAlt: "alt 3", ImageURL: "https://imgs.xkcd.com/comics/3.png"},
+		{Number: 2, Title: "Second", Year: "2006", Month: "1", Day: "2", ImageAlt: "alt 2", ImageURL: "https://imgs.xkcd.com/comics/2.png"},
+	}
+}
+
+func TestGenerateAtom(t *testing.T) {
+	data, err := Generate(Atom, setupComics(), 2, "http://localhost/atom.xml")
+
+	if err != nil {
+		t.Fatalf("expected err to be nil, got %v", err)
+	}
+
+	var got atomFeed
+
+	if err := xml.Unmarshal(data, &got); err != nil {
+		t.Fatalf("expected valid XML, got %v", err)
+	}
+
+	if len(got.Entries) != 2 {
+		t.Fatalf("expected 2 entries, got %d", len(got.Entries))
+	}
+
+	if got.Entries[0].Title != "Third" || got.Entries[1].Title != "Second" {
+		t.Errorf("expected latest comics first, got %s, %s", got.Entries[0].Title, got.Entries[1].Title)
+	}
+
+	if got.Author.Name == "" {
+		t.Errorf("expected feed author")
+	}
+
+	if len(got.Links) != 2 || got.Links[1].Rel != "self" || got.Links[1].Href != "http://localhost/atom.xml" {
+		t.Errorf("expected alternate and self links, got %v", got.Links)
+	}
+
+	if got.Updated != "2006-01-03T00:00:00Z" {
+		t.Errorf("expected feed updated at the latest comic, got %s", got.Updated)
+	}
+
+	if !strings.Contains(got.Entries[0].Content.Body, `src="https://imgs.xkcd.com/comics/3.png"`) {
+		t.Errorf("expected embedded image, got %s", got.Entries[0].Content.Body)
+	}
+}
+
+func TestGenerateRSS(t *testing.T) {
+	data, err := Generate(RSS, setupComics(), 0, "")
+
+	if err != nil {
+		t.Fatalf("expected err to be nil, got %v", err)
+	}
+
+	var got rssFeed
+
+	if err := xml.Unmarshal(data, &got); err != nil {
+		t.Fatalf("expected valid XML, got %v", err)
+	}
+
+	if len(got.Channel.Items) != 3 {
+		t.Fatalf("expected 3 items, got %d", len(got.Channel.Items))
+	}
+
+	item := got.Channel.Items[0]
+
+	if item.Link != "https://xkcd.com/3/" || item.PubDate != "Tue, 03 Jan 2006 00:00:00 +0000" {
+		t.Errorf("unexpected item %+v", item)
+	}
+}
+
+func TestGenerateUnknownFormat(t *testing.T) {
+	if _, err := Generate("json", setupComics(), 1, ""); err == nil {
+		t.Errorf("expected error, got nil")
+	}
+}
+
+func TestGenerateMalformedDate(t *testing.T) {
+	comics := append(setupComics(), comic.XKCD{Number: 4, Title: "Fourth", Year: "2006", Month: "13", Day: "1"})
+
+	data, err := Generate(Atom, comics, 0, "")
+
+	if err != nil {
+		t.Fatalf("expected err to be nil, got %v", err)
+	}
+
+	var atom atomFeed
+
+	if err := xml.Unmarshal(data, &atom); err != nil {
+		t.Fatalf("expected valid XML, got %v", err)
+	}
+
+	if atom.Entries[0].Updated != atom.Updated || atom.Updated != "2006-01-03T00:00:00Z" {
+		t.Errorf("expected malformed date to fall back to the feed update time, got %s", atom.Entries[0].Updated)
+	}
+
+	data, err = Generate(RSS, comics, 0, "")
+
+	if err != nil {
+		t.Fatalf("expected err to be nil, got %v", err)
+	}
+
+	var rss rssFeed
+
+	if err := xml.Unmarshal(data, &rss); err != nil {
+		t.Fatalf("expected valid XML, got %v", err)
+	}
+
+	if rss.Channel.Items[0].PubDate != "" {
+		t.Errorf("expected no pubDate for malformed date, got %s", rss.Channel.Items[0].PubDate)
+	}
+
+	if strings.Contains(string(data), "0001") {
+		t.Errorf("expected no zero dates in the feed")
+	}
+}
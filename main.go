@@ -4,18 +4,24 @@ import (
 	"flag"
 	"fmt"
 	"log"
+	"os"
 	"sync"
 	"time"
 
 	"xkcd2/comic"
+	"xkcd2/config"
 	"xkcd2/persistence"
+	"xkcd2/stats"
 	"xkcd2/tools/logger"
+	"xkcd2/tools/util"
 )
 
 var (
 	logging = flag.Bool("l", false, "creates a log files")
 	stat    = flag.Bool("s", false, "show offline index stats")
-	dump    = flag.Bool("d", false, "output comic numbers, year, month and date (used only with -s)")
+	dump    = flag.Bool("d", false, "output comic numbers and publication dates (used only with -s)")
+	asJSON  = flag.Bool("j", false, "output index stats as JSON (used only with -s)")
+	feeds   = flag.Bool("f", false, "writes Atom and RSS feeds of the latest comics after syncing")
 )
 
 var (
@@ -24,7 +30,8 @@ var (
 	comicChan     chan *comic.XKCD // downloaded comic
 	statusChan    <-chan time.Time // time to refresh the progress status
 
-	comics comic.Comics
+	comics        comic.Comics
+	historyLoaded bool // the history file was read, so it can be written back
 )
 
 // func init() {
@@ -32,6 +39,7 @@ var (
 // }
 
 func main() {
+	flag.Usage = usage
 	flag.Parse()
 	logger.Initialize(*logging)
 
@@ -39,26 +47,56 @@ func main() {
 
 	loadComics()
 
+	if flag.NArg() > 0 {
+		runCommand(flag.Arg(0), flag.Args()[1:])
+		return
+	}
+
 	if !*stat {
-		// no flag
+		// no flag, the same as the sync command without arguments
 		start := time.Now()
 		doSync()
-		comics.Sort()
-		writeComics()
-
-		fmt.Printf("\nDONE in %s\n", time.Since(start))
-		fmt.Printf("\nTotal comics: %d\n", comics.Len())
+		finishSync(start)
 	} else {
 		// -s flag
-		if *dump {
+		if *dump && !*asJSON {
 			// -d flag
-			for _, item := range comics.GetAll() {
-				fmt.Printf("%d,%s,%s,%s\n",
-					item.Number, item.Year, item.Month, item.Day)
-			}
+			comics.Query().Each(func(item comic.XKCD) bool {
+				date, err := item.Date()
+
+				if err != nil {
+					fmt.Printf("%d,%v\n", item.Number, err)
+				} else {
+					fmt.Printf("%d,%s,%s\n", item.Number, date.Format(comic.DateLayout), date.Weekday())
+				}
+
+				return true
+			})
 		}
 
-		fmt.Printf("\nIndex status: %d\n", comics.Len())
+		printStats()
+	}
+}
+
+// printStats writes the statistics report of the offline index to the standard output.
+func printStats() {
+	lastSync, err := persistence.ReadSyncTime()
+
+	if err != nil {
+		log.Println(err)
+	}
+
+	report := stats.Build(comics.GetAll(), util.GetIndexFile(), lastSync)
+
+	if *asJSON {
+		err = report.WriteJSON(os.Stdout)
+	} else {
+		fmt.Println()
+		err = report.WriteText(os.Stdout)
+	}
+
+	if err != nil {
+		log.Fatal(err)
 	}
 }
 
@@ -69,15 +107,35 @@ func doSync() {
 	comicChan = make(chan *comic.XKCD)
 	statusChan = time.Tick(500 * time.Millisecond)
 
-	go monitor()
+	done := make(chan struct{})
+	go monitor(done)
 
 	lastComicNum := getLatestComicNum()
 
 	fetchComics(lastComicNum)
 
-	// Channel closer
+	// Channel closer, then wait for the monitor to add the last comic and stop
 	wg.Wait()
 	closeChannels()
+	<-done
+}
+
+// finishSync writes the synced comics, the time of the sync and the feeds and prints the summary.
+func finishSync(start time.Time) {
+	writeComics()
+
+	if err := persistence.WriteSyncTime(time.Now()); err != nil {
+		log.Println(err)
+	}
+
+	if *feeds {
+		if err := writeFeeds(); err != nil {
+			log.Println(err)
+		}
+	}
+
+	fmt.Printf("\nDONE in %s\n", time.Since(start))
+	fmt.Printf("\nTotal comics: %d\n", comics.Len())
 }
 
 // fetchComics function does the actual hard work of downloading all the missing comics.
@@ -90,7 +148,7 @@ func fetchComics(lastComicNum int) {
 	semaphore := make(chan struct{}, 20)
 
 	for i := 1; i < lastComicNum; i++ {
-		if comics.Contains(i) {
+		if config.IsAbsent(i) || comics.Contains(i) {
 			continue
 		}
 
@@ -107,6 +165,8 @@ func fetchComics(lastComicNum int) {
 			defer func() { <-semaphore }()
 
 			if err = xkcd.Download(comicNum); err != nil {
+				// the comic stays missing, run the repair command to retry
+				logger.Info(fmt.Sprintf("fetchComics %d: %v", comicNum, err))
 				return
 			}
 
@@ -126,17 +186,41 @@ func loadComics() {
 	}
 
 	if temp != nil {
-		comics.Load(temp)
+		unique, duplicates := comic.Dedup(temp)
+
+		if len(duplicates) > 0 {
+			log.Printf("index file contains %d duplicated comics, run the dedup command for details", len(duplicates))
+		}
+
+		comics.Load(unique)
+	}
+
+	history, err := persistence.ReadHistoryFile()
+
+	if err != nil {
+		// keep the unreadable history file instead of overwriting it in writeComics
+		log.Println(err)
+	} else {
+		comics.LoadHistory(history)
+		historyLoaded = true
 	}
 }
 
-// Writes the comics back to the index file
+// Writes the comics back to the index file and their revision history to the history file
 func writeComics() {
 	err := persistence.WriteIndexFile(comics.GetAll())
 
 	if err != nil {
 		log.Fatal(err)
 	}
+
+	if !historyLoaded {
+		return
+	}
+
+	if err = persistence.WriteHistoryFile(comics.Histories()); err != nil {
+		log.Fatal(err)
+	}
 }
 
 // Retrieves the latest comic and passes the information to lastComicChan and comicChan channels.
@@ -156,23 +240,28 @@ func getLatestComicNum() int {
 }
 
 // monitor function monitors the channels and does something with the
-// data that arrives on each channel.
-func monitor() {
+// data that arrives on each channel. It returns, closing done, when comicChan is closed.
+func monitor(done chan<- struct{}) {
+	defer close(done)
+
 	var lastComicNum int
+	lastComic := lastComicChan
 
 	for {
 		select {
-		case comicNum, ok := <-lastComicChan:
-			if ok {
-				lastComicNum = comicNum
-				close(lastComicChan)
+		case comicNum := <-lastComic:
+			lastComicNum = comicNum
+			// a nil channel is never selected again
+			lastComic = nil
+
+		case item, ok := <-comicChan:
+			if !ok {
+				fmt.Println()
+				return
 			}
 
-		case item := <-comicChan:
-			if item != nil {
-				logger.Info(fmt.Sprintf("Adding %d\n", (*item).Number))
-				comics.Add(item)
-			}
+			logger.Info(fmt.Sprintf("Adding %d\n", (*item).Number))
+			comics.Add(item)
 
 		case <-statusChan:
 			result := float64(comics.Len()) / float64(lastComicNum) * 100
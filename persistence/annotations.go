@@ -0,0 +1,53 @@
+package persistence
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"xkcd2/annotation"
+	"xkcd2/tools/logger"
+	"xkcd2/tools/util"
+)
+
+// Writes the annotations into the annotations file. The file is JSON, so it can be edited by hand and
+// kept under version control.
+func WriteAnnotationsFile(annotations map[int]annotation.Annotation) error {
+	defer logger.Trace("WriteAnnotationsFile")()
+
+	data, err := json.MarshalIndent(annotations, "", "  ")
+
+	if err != nil {
+		return fmt.Errorf("json WriteAnnotationsFile: %v", err)
+	}
+
+	if err := util.WriteFileAtomic(util.GetAnnotationsFile(), data); err != nil {
+		return fmt.Errorf("WriteAnnotationsFile: %v", err)
+	}
+
+	return nil
+}
+
+// Reads the annotations from the annotations file. A missing file means that no comic is annotated yet.
+func ReadAnnotationsFile() (map[int]annotation.Annotation, error) {
+	defer logger.Trace("ReadAnnotationsFile")()
+
+	data, err := ioutil.ReadFile(util.GetAnnotationsFile())
+
+	if os.IsNotExist(err) {
+		return map[int]annotation.Annotation{}, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("ReadAnnotationsFile: %v", err)
+	}
+
+	var annotations map[int]annotation.Annotation
+
+	if err := json.Unmarshal(data, &annotations); err != nil {
+		return nil, fmt.Errorf("json ReadAnnotationsFile: %v", err)
+	}
+
+	return annotations, nil
+}
@@ -0,0 +1,58 @@
+package persistence
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"xkcd2/tools/imaging"
+	"xkcd2/tools/logger"
+	"xkcd2/tools/util"
+)
+
+// Writes data into the blob store and returns its reference, the SHA-256 checksum of data. The blobs are
+// addressed by their content, so the same file downloaded twice is stored only once.
+func WriteBlob(data []byte) (string, error) {
+	defer logger.Trace("WriteBlob")()
+
+	ref := imaging.Hash(data)
+	filename := blobPath(ref)
+
+	if _, err := os.Stat(filename); err == nil {
+		return ref, nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(filename), 0755); err != nil {
+		return "", fmt.Errorf("WriteBlob: %v", err)
+	}
+
+	if err := util.WriteFileAtomic(filename, data); err != nil {
+		return "", fmt.Errorf("WriteBlob: %v", err)
+	}
+
+	return ref, nil
+}
+
+// Reads the blob with the reference returned by WriteBlob.
+func ReadBlob(ref string) ([]byte, error) {
+	defer logger.Trace("ReadBlob")()
+
+	if len(ref) < 3 || strings.ContainsAny(ref, `/\.`) {
+		return nil, fmt.Errorf("ReadBlob: invalid reference %q", ref)
+	}
+
+	data, err := ioutil.ReadFile(blobPath(ref))
+
+	if err != nil {
+		return nil, fmt.Errorf("ReadBlob: %v", err)
+	}
+
+	return data, nil
+}
+
+// blobPath returns the filename of the blob, the blobs are spread into folders by the first two characters.
+func blobPath(ref string) string {
+	return filepath.Join(util.GetBlobsFolder(), ref[:2], ref)
+}
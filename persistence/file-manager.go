@@ -21,13 +21,14 @@ func init() {
 	}
 }
 
-// Writes comics into an index file. This process will recreate the file every time.
+// Writes comics into an index file. This process will recreate the file every time, the file is truncated
+// so that the comics of a longer previous index are not read back after the new ones.
 // Better approach would be to find what has been written before and append the new items.
 // (Will be done later)
 func WriteIndexFile(comics []comic.XKCD) error {
 	defer logger.Trace("WriteIndexFile")()
 
-	file, err := os.OpenFile(util.GetIndexFile(), os.O_CREATE|os.O_WRONLY, 0777)
+	file, err := os.OpenFile(util.GetIndexFile(), os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0777)
 
 	if err != nil {
 		return fmt.Errorf("gob WriteIndexFile: %v", err)
@@ -53,9 +54,14 @@ func WriteIndexFile(comics []comic.XKCD) error {
 
 // Reads the index file and loads all the comics into a slice.
 func ReadIndexFile() ([]comic.XKCD, error) {
-	defer logger.Trace("ReadingIndexFile")()
+	return ReadIndexFileFrom(util.GetIndexFile())
+}
+
+// Reads an index file at filename, e.g. copied from another machine, and loads all the comics into a slice.
+func ReadIndexFileFrom(filename string) ([]comic.XKCD, error) {
+	defer logger.Trace(fmt.Sprintf("ReadingIndexFile(%s)", filename))()
 
-	file, err := os.OpenFile(util.GetIndexFile(), os.O_RDONLY, 0777)
+	file, err := os.OpenFile(filename, os.O_RDONLY, 0777)
 
 	if err != nil {
 		return nil, fmt.Errorf("gob ReadIndexFile: %v", err)
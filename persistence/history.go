@@ -0,0 +1,57 @@
+package persistence
+
+import (
+	"encoding/gob"
+	"fmt"
+	"os"
+
+	"xkcd2/comic"
+	"xkcd2/tools/logger"
+	"xkcd2/tools/util"
+)
+
+// Writes the revision history of the comics into the history file. The history is kept apart from
+// the index file, so the index format does not change.
+func WriteHistoryFile(history map[int][]comic.Revision) error {
+	defer logger.Trace("WriteHistoryFile")()
+
+	file, err := os.OpenFile(util.GetHistoryFile(), os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+
+	if err != nil {
+		return fmt.Errorf("gob WriteHistoryFile: %v", err)
+	}
+
+	defer file.Close()
+
+	if err := gob.NewEncoder(file).Encode(history); err != nil {
+		return fmt.Errorf("gob encode: %v", err)
+	}
+
+	return nil
+}
+
+// Reads the revision history from the history file. A missing file means that no comic has changed yet,
+// so it returns an empty history without an error.
+func ReadHistoryFile() (map[int][]comic.Revision, error) {
+	defer logger.Trace("ReadHistoryFile")()
+
+	file, err := os.Open(util.GetHistoryFile())
+
+	if os.IsNotExist(err) {
+		return map[int][]comic.Revision{}, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("gob ReadHistoryFile: %v", err)
+	}
+
+	defer file.Close()
+
+	var history map[int][]comic.Revision
+
+	if err := gob.NewDecoder(file).Decode(&history); err != nil {
+		return nil, fmt.Errorf("gob decode: %v", err)
+	}
+
+	return history, nil
+}
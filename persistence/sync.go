@@ -0,0 +1,47 @@
+package persistence
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"xkcd2/tools/logger"
+	"xkcd2/tools/util"
+)
+
+// Writes the time of the last sync into the sync file. Other commands also rewrite the index file,
+// so its modification time is not the time of the last sync.
+func WriteSyncTime(t time.Time) error {
+	defer logger.Trace("WriteSyncTime")()
+
+	if err := os.WriteFile(util.GetSyncFile(), []byte(t.Format(time.RFC3339)+"\n"), 0644); err != nil {
+		return fmt.Errorf("WriteSyncTime: %v", err)
+	}
+
+	return nil
+}
+
+// Reads the time of the last sync from the sync file. It returns nil without an error if the index was
+// never synced since the file was introduced.
+func ReadSyncTime() (*time.Time, error) {
+	defer logger.Trace("ReadSyncTime")()
+
+	data, err := os.ReadFile(util.GetSyncFile())
+
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("ReadSyncTime: %v", err)
+	}
+
+	t, err := time.Parse(time.RFC3339, strings.TrimSpace(string(data)))
+
+	if err != nil {
+		return nil, fmt.Errorf("ReadSyncTime: %v", err)
+	}
+
+	return &t, nil
+}
@@ -0,0 +1,130 @@
+// Package playlist keeps named, ordered lists of comic numbers, such as "onboarding" or "git jokes",
+// and renders them as JSON, HTML or Markdown.
+package playlist
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"xkcd2/config"
+)
+
+// Playlist is a named list of comic numbers in the order they were added.
+type Playlist struct {
+	Name        string    `json:"name"`
+	Description string    `json:"description,omitempty"`
+	Comics      []int     `json:"comics"`
+	Created     time.Time `json:"created"`
+	Updated     time.Time `json:"updated"`
+}
+
+// Problem is a comic number of a playlist that cannot be shown.
+type Problem struct {
+	Number int
+	Reason string
+}
+
+// namePattern are the valid playlist names: letters, digits, spaces, dashes and underscores.
+var namePattern = regexp.MustCompile(`^[\p{L}\p{N}][\p{L}\p{N} _-]{0,63}$`)
+
+// now returns the current time, it is replaced in tests.
+var now = time.Now
+
+// New creates an empty playlist.
+func New(name, description string) (*Playlist, error) {
+	if err := ValidateName(name); err != nil {
+		return nil, err
+	}
+
+	created := now()
+
+	return &Playlist{Name: strings.TrimSpace(name), Description: description, Comics: []int{}, Created: created, Updated: created}, nil
+}
+
+// ValidateName returns an error if name cannot be used as a playlist name.
+func ValidateName(name string) error {
+	if !namePattern.MatchString(strings.TrimSpace(name)) {
+		return fmt.Errorf("invalid playlist name %q, use letters, digits, spaces, - and _", name)
+	}
+
+	return nil
+}
+
+// Add appends the comics to the playlist, or inserts them before position at (counted from 1) if at is
+// greater than 0. Comics already in the playlist are not added again. It returns the number of comics added.
+func (p *Playlist) Add(at int, numbers ...int) int {
+	var added []int
+
+	for _, num := range numbers {
+		if !p.Contains(num) && !containsInt(added, num) {
+			added = append(added, num)
+		}
+	}
+
+	if at <= 0 || at > len(p.Comics) {
+		p.Comics = append(p.Comics, added...)
+	} else {
+		p.Comics = append(p.Comics[:at-1], append(added, p.Comics[at-1:]...)...)
+	}
+
+	if len(added) > 0 {
+		p.Updated = now()
+	}
+
+	return len(added)
+}
+
+// Remove removes the comics from the playlist and returns the number of comics removed.
+func (p *Playlist) Remove(numbers ...int) int {
+	kept := p.Comics[:0]
+
+	for _, num := range p.Comics {
+		if !containsInt(numbers, num) {
+			kept = append(kept, num)
+		}
+	}
+
+	removed := len(p.Comics) - len(kept)
+	p.Comics = kept
+
+	if removed > 0 {
+		p.Updated = now()
+	}
+
+	return removed
+}
+
+// Contains returns true if the comic is in the playlist.
+func (p *Playlist) Contains(comicNum int) bool {
+	return containsInt(p.Comics, comicNum)
+}
+
+// Validate checks the comics of the playlist against the index. contains reports whether a comic is in the index.
+func (p *Playlist) Validate(contains func(comicNum int) bool) []Problem {
+	var result []Problem
+
+	for _, num := range p.Comics {
+		switch {
+		case num < 1:
+			result = append(result, Problem{num, "invalid comic number"})
+		case config.IsAbsent(num):
+			result = append(result, Problem{num, "never published"})
+		case !contains(num):
+			result = append(result, Problem{num, "not in the index"})
+		}
+	}
+
+	return result
+}
+
+func containsInt(numbers []int, n int) bool {
+	for _, num := range numbers {
+		if num == n {
+			return true
+		}
+	}
+
+	return false
+}
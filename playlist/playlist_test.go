@@ -0,0 +1,112 @@
+package playlist
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+
+	"xkcd2/comic"
+)
+
+func TestAddRemove(t *testing.T) {
+	p, err := New("onboarding", "")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if added := p.Add(0, 1, 2, 2, 3); added != 3 {
+		t.Errorf("expected 3 comics added, got %d", added)
+	}
+
+	p.Add(2, 10, 1)
+	p.Add(100, 20)
+
+	if want := []int{1, 10, 2, 3, 20}; !reflect.DeepEqual(p.Comics, want) {
+		t.Errorf("expected %v, got %v", want, p.Comics)
+	}
+
+	if removed := p.Remove(2, 20, 99); removed != 2 {
+		t.Errorf("expected 2 comics removed, got %d", removed)
+	}
+
+	if want := []int{1, 10, 3}; !reflect.DeepEqual(p.Comics, want) {
+		t.Errorf("expected %v, got %v", want, p.Comics)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	p := &Playlist{Name: "test", Comics: []int{1, 404, 7, 0}}
+	index := map[int]bool{1: true}
+
+	got := p.Validate(func(n int) bool { return index[n] })
+	want := []Problem{{404, "never published"}, {7, "not in the index"}, {0, "invalid comic number"}}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+
+	for _, name := range []string{"", " ", "../etc", "a/b", strings.Repeat("x", 65)} {
+		if err := ValidateName(name); err == nil {
+			t.Errorf("expected %q to be an invalid name", name)
+		}
+	}
+}
+
+func TestStore(t *testing.T) {
+	store := NewStore(t.TempDir())
+	p, _ := New("Git jokes", "version control")
+	p.Add(0, 1597, 1296)
+
+	if err := store.Write(p); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := store.Read("Git jokes")
+
+	if err != nil || got.Description != "version control" || !reflect.DeepEqual(got.Comics, p.Comics) {
+		t.Errorf("expected the written playlist, got %+v, %v", got, err)
+	}
+
+	if names, err := store.List(); err != nil || !reflect.DeepEqual(names, []string{"Git jokes"}) {
+		t.Errorf("expected one playlist, got %v, %v", names, err)
+	}
+
+	if err := store.Delete("git jokes"); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := store.Read("Git jokes"); err != ErrNotFound {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestWriteMarkdown(t *testing.T) {
+	p := &Playlist{Name: "git", Description: "Jokes", Comics: []int{1597, 2}}
+	entries := Resolve(p, func(n int) *comic.XKCD {
+		if n == 1597 {
+			return &comic.XKCD{Number: 1597, Title: "Git [sic]"}
+		}
+
+		return nil
+	})
+
+	var buf bytes.Buffer
+
+	if err := Write(&buf, Markdown, p, entries, Links{}); err != nil {
+		t.Fatal(err)
+	}
+
+	want := "# git\n\nJokes\n\n1. [1597: Git \\[sic\\]](https://xkcd.com/1597/)\n2. [2](https://xkcd.com/2/) (not in the index)\n"
+
+	if buf.String() != want {
+		t.Errorf("expected %q, got %q", want, buf.String())
+	}
+
+	buf.Reset()
+
+	if err := Write(&buf, HTML, p, entries, Links{}); err != nil || !strings.Contains(buf.String(), `<a href="https://xkcd.com/1597/">1597: Git [sic]</a>`) {
+		t.Errorf("unexpected HTML %s, %v", buf.String(), err)
+	}
+}
@@ -0,0 +1,189 @@
+package playlist
+
+import (
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"io"
+	"strings"
+
+	"xkcd2/comic"
+	"xkcd2/config"
+)
+
+// Formats of Write.
+const (
+	JSON     = "json"
+	HTML     = "html"
+	Markdown = "md"
+)
+
+// Entry is a comic of a playlist resolved against the index. Comic is nil if the comic is not in the index.
+type Entry struct {
+	Number int
+	Comic  *comic.XKCD
+}
+
+// Links are the URLs used in the rendered playlists. The default links point to the xkcd web site.
+type Links struct {
+	Comic func(comicNum int) string     // page of the comic
+	Image func(xkcd *comic.XKCD) string // image shown for the comic
+}
+
+// Resolve looks up the comics of the playlist with get, which returns nil for a comic that is not in the index.
+func Resolve(p *Playlist, get func(comicNum int) *comic.XKCD) []Entry {
+	result := make([]Entry, 0, len(p.Comics))
+
+	for _, num := range p.Comics {
+		result = append(result, Entry{num, get(num)})
+	}
+
+	return result
+}
+
+// Write renders the playlist with its entries in format.
+func Write(w io.Writer, format string, p *Playlist, entries []Entry, links Links) error {
+	links = links.withDefaults()
+
+	switch format {
+	case JSON:
+		return writeJSON(w, p, entries, links)
+	case HTML:
+		return WriteHTML(w, p, entries, links, "")
+	case Markdown:
+		return writeMarkdown(w, p, entries, links)
+	}
+
+	return fmt.Errorf("playlist: unknown format %q", format)
+}
+
+func (l Links) withDefaults() Links {
+	if l.Comic == nil {
+		l.Comic = func(comicNum int) string { return fmt.Sprintf("%s/%d/", config.HomeURL, comicNum) }
+	}
+
+	if l.Image == nil {
+		l.Image = func(xkcd *comic.XKCD) string { return xkcd.ImageURL }
+	}
+
+	return l
+}
+
+// jsonEntry is a comic of the JSON export.
+type jsonEntry struct {
+	Number int    `json:"num"`
+	Title  string `json:"title,omitempty"`
+	URL    string `json:"url"`
+	Image  string `json:"img,omitempty"`
+	Alt    string `json:"alt,omitempty"`
+	Found  bool   `json:"found"`
+}
+
+func writeJSON(w io.Writer, p *Playlist, entries []Entry, links Links) error {
+	doc := struct {
+		*Playlist
+		Entries []jsonEntry `json:"entries"`
+	}{Playlist: p, Entries: make([]jsonEntry, 0, len(entries))}
+
+	for _, e := range entries {
+		entry := jsonEntry{Number: e.Number, URL: links.Comic(e.Number), Found: e.Comic != nil}
+
+		if e.Comic != nil {
+			entry.Title, entry.Image, entry.Alt = e.Comic.Title, links.Image(e.Comic), e.Comic.ImageAlt
+		}
+
+		doc.Entries = append(doc.Entries, entry)
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+
+	if err := encoder.Encode(doc); err != nil {
+		return fmt.Errorf("playlist json: %v", err)
+	}
+
+	return nil
+}
+
+func writeMarkdown(w io.Writer, p *Playlist, entries []Entry, links Links) error {
+	var sb strings.Builder
+
+	fmt.Fprintf(&sb, "# %s\n\n", p.Name)
+
+	if p.Description != "" {
+		fmt.Fprintf(&sb, "%s\n\n", p.Description)
+	}
+
+	for i, e := range entries {
+		if e.Comic == nil {
+			fmt.Fprintf(&sb, "%d. [%d](%s) (not in the index)\n", i+1, e.Number, links.Comic(e.Number))
+			continue
+		}
+
+		title := strings.NewReplacer("[", `\[`, "]", `\]`).Replace(e.Comic.Title)
+		fmt.Fprintf(&sb, "%d. [%d: %s](%s)\n", i+1, e.Number, title, links.Comic(e.Number))
+	}
+
+	_, err := io.WriteString(w, sb.String())
+
+	return err
+}
+
+var htmlTemplate = template.Must(template.New("playlist").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>{{.Playlist.Name}}</title>
+<style>
+body { font-family: sans-serif; max-width: 960px; margin: 0 auto; padding: 1em; }
+ol { padding-left: 1.5em; }
+li { margin: 1em 0; }
+li img { display: block; max-width: 100%; margin-top: .5em; }
+.missing { color: #999; }
+</style>
+</head>
+<body>
+{{if .Back}}<p><a href="{{.Back}}">&lt; Playlists</a></p>{{end}}
+<h1>{{.Playlist.Name}}</h1>
+{{if .Playlist.Description}}<p>{{.Playlist.Description}}</p>{{end}}
+<ol>
+{{range .Entries}}{{if .Comic}}<li><a href="{{.URL}}">{{.Comic.Number}}: {{.Comic.Title}}</a>
+<img src="{{.Image}}" alt="{{.Comic.Title}}" title="{{.Comic.ImageAlt}}" loading="lazy"></li>
+{{else}}<li class="missing">{{.Number}}: not in the index</li>
+{{end}}{{end}}</ol>
+</body>
+</html>
+`))
+
+// WriteHTML renders the playlist as a standalone HTML page. back is an optional link to the list of playlists.
+func WriteHTML(w io.Writer, p *Playlist, entries []Entry, links Links, back string) error {
+	links = links.withDefaults()
+
+	type htmlEntry struct {
+		Entry
+		URL   string
+		Image string
+	}
+
+	data := struct {
+		Playlist *Playlist
+		Entries  []htmlEntry
+		Back     string
+	}{Playlist: p, Back: back}
+
+	for _, e := range entries {
+		entry := htmlEntry{Entry: e, URL: links.Comic(e.Number)}
+
+		if e.Comic != nil {
+			entry.Image = links.Image(e.Comic)
+		}
+
+		data.Entries = append(data.Entries, entry)
+	}
+
+	if err := htmlTemplate.Execute(w, data); err != nil {
+		return fmt.Errorf("playlist html: %v", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,149 @@
+package playlist
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// ErrNotFound is returned by Store.Read for a playlist that does not exist.
+var ErrNotFound = fmt.Errorf("playlist not found")
+
+// Store keeps the playlists as JSON files in a folder, one file per playlist.
+type Store struct {
+	dir string
+}
+
+// NewStore creates a store of playlists in dir. The folder is created when the first playlist is written.
+func NewStore(dir string) *Store {
+	return &Store{dir: dir}
+}
+
+// List returns the names of the stored playlists ordered by name.
+func (s *Store) List() ([]string, error) {
+	files, err := ioutil.ReadDir(s.dir)
+
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("playlists: %v", err)
+	}
+
+	var result []string
+
+	for _, file := range files {
+		if file.IsDir() || filepath.Ext(file.Name()) != ".json" {
+			continue
+		}
+
+		p, err := s.readFile(filepath.Join(s.dir, file.Name()))
+
+		if err != nil {
+			return nil, err
+		}
+
+		result = append(result, p.Name)
+	}
+
+	sort.Strings(result)
+
+	return result, nil
+}
+
+// Read returns the playlist with name, or ErrNotFound.
+func (s *Store) Read(name string) (*Playlist, error) {
+	if err := ValidateName(name); err != nil {
+		return nil, err
+	}
+
+	p, err := s.readFile(s.path(name))
+
+	if os.IsNotExist(err) {
+		return nil, ErrNotFound
+	}
+
+	return p, err
+}
+
+// Write saves the playlist, replacing the stored playlist with the same name.
+func (s *Store) Write(p *Playlist) error {
+	if err := ValidateName(p.Name); err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(p, "", "  ")
+
+	if err != nil {
+		return fmt.Errorf("playlist %s: %v", p.Name, err)
+	}
+
+	if err := os.MkdirAll(s.dir, 0755); err != nil {
+		return fmt.Errorf("playlists: %v", err)
+	}
+
+	filename := s.path(p.Name)
+	temp := filename + ".tmp"
+
+	if err := ioutil.WriteFile(temp, data, 0644); err != nil {
+		return fmt.Errorf("playlist %s: %v", p.Name, err)
+	}
+
+	if err := os.Rename(temp, filename); err != nil {
+		return fmt.Errorf("playlist %s: %v", p.Name, err)
+	}
+
+	return nil
+}
+
+// Delete removes the playlist with name.
+func (s *Store) Delete(name string) error {
+	if err := ValidateName(name); err != nil {
+		return err
+	}
+
+	err := os.Remove(s.path(name))
+
+	if os.IsNotExist(err) {
+		return ErrNotFound
+	}
+
+	if err != nil {
+		return fmt.Errorf("playlist %s: %v", name, err)
+	}
+
+	return nil
+}
+
+// path returns the filename of the playlist. Names that differ only in case or in spaces and dashes share the file.
+func (s *Store) path(name string) string {
+	slug := strings.ToLower(strings.Join(strings.Fields(strings.TrimSpace(name)), "-"))
+
+	return filepath.Join(s.dir, slug+".json")
+}
+
+// readFile reads a playlist file.
+func (s *Store) readFile(filename string) (*Playlist, error) {
+	data, err := ioutil.ReadFile(filename)
+
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, err
+		}
+
+		return nil, fmt.Errorf("playlists: %v", err)
+	}
+
+	var p Playlist
+
+	if err := json.Unmarshal(data, &p); err != nil {
+		return nil, fmt.Errorf("playlist %s: %v", filepath.Base(filename), err)
+	}
+
+	return &p, nil
+}
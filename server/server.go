@@ -0,0 +1,316 @@
+// Package server serves the offline collection over HTTP: the static site generated by package site,
+// the feeds generated by package feed, the thumbnails of the stored images and the playlists.
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"xkcd2/comic"
+	"xkcd2/config"
+	"xkcd2/feed"
+	"xkcd2/playlist"
+	"xkcd2/site"
+	"xkcd2/tools/imaging"
+	"xkcd2/tools/logger"
+)
+
+// Server is an http.Handler serving the content of a comics collection.
+type Server struct {
+	comics    *comic.Comics
+	thumbs    *imaging.ThumbCache
+	playlists *playlist.Store
+	mux       *http.ServeMux
+}
+
+// playlistImageSize is the size of the thumbnails shown on the playlist pages.
+const playlistImageSize = 400
+
+// New creates a Server for comics. The static site is served from siteDir, the thumbnails are
+// generated into the thumbs cache and the playlists are read from the playlists store.
+func New(comics *comic.Comics, siteDir string, thumbs *imaging.ThumbCache, playlists *playlist.Store) *Server {
+	s := &Server{comics: comics, thumbs: thumbs, playlists: playlists, mux: http.NewServeMux()}
+
+	s.mux.HandleFunc("/atom.xml", s.feedHandler(feed.Atom, "application/atom+xml"))
+	s.mux.HandleFunc("/rss.xml", s.feedHandler(feed.RSS, "application/rss+xml"))
+	s.mux.HandleFunc("/comics.json", s.comicsHandler)
+	s.mux.HandleFunc("/thumbs/", s.thumbHandler)
+	s.mux.HandleFunc("/playlists/", s.playlistHandler)
+	s.mux.Handle("/", http.FileServer(http.Dir(siteDir)))
+
+	return s
+}
+
+// ServeHTTP dispatches the request to the handler of the matching path.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	logger.Info(fmt.Sprintf("%s %s", r.Method, r.URL))
+
+	s.mux.ServeHTTP(w, r)
+}
+
+// feedHandler renders the feed in format from the current state of the collection. The number of
+// comics defaults to config.FeedSize and can be changed with the n query parameter.
+func (s *Server) feedHandler(format string, contentType string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		count := config.FeedSize
+
+		if value := r.URL.Query().Get("n"); value != "" {
+			n, err := strconv.Atoi(value)
+
+			if err != nil {
+				http.Error(w, fmt.Sprintf("invalid n: %v", err), http.StatusBadRequest)
+				return
+			}
+
+			count = n
+		}
+
+		scheme := "http"
+
+		if r.TLS != nil {
+			scheme = "https"
+		}
+
+		self := fmt.Sprintf("%s://%s%s", scheme, r.Host, r.URL.Path)
+		data, err := feed.Generate(format, s.comics.GetAll(), count, self)
+
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", contentType+"; charset=utf-8")
+		w.Write(data)
+	}
+}
+
+// comicSummary is the JSON representation of a comic returned by the comics endpoint.
+type comicSummary struct {
+	Number   int    `json:"num"`
+	Title    string `json:"title"`
+	Date     string `json:"date,omitempty"`
+	ImageURL string `json:"img"`
+	ImageAlt string `json:"alt"`
+}
+
+// comicsPage is a page of comics with the cursor of the next page.
+type comicsPage struct {
+	Comics []comicSummary `json:"comics"`
+	Next   string         `json:"next,omitempty"`
+}
+
+// comicsHandler returns a page of comics as JSON. The query parameters are from, to, order (number,
+// date or title), desc, size (default 50) and cursor, the same as in the ls command, and q, a search
+// query as in the search command without the annotation fields.
+func (s *Server) comicsHandler(w http.ResponseWriter, r *http.Request) {
+	params := r.URL.Query()
+
+	order, err := comic.ParseOrder(params.Get("order"))
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var from, to int
+	size := 50
+
+	for name, value := range map[string]*int{"from": &from, "to": &to, "size": &size} {
+		if params.Get(name) == "" {
+			continue
+		}
+
+		if *value, err = strconv.Atoi(params.Get(name)); err != nil {
+			http.Error(w, fmt.Sprintf("invalid %s: %v", name, err), http.StatusBadRequest)
+			return
+		}
+	}
+
+	query := s.comics.Query().Range(from, to)
+
+	if q := params.Get("q"); q != "" {
+		filter, err := comic.ParseSearch(q, nil)
+
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		query = query.Filter(filter)
+	}
+
+	desc := params.Get("desc") == "true"
+	items, next, err := query.OrderBy(order, desc).Page(params.Get("cursor"), size)
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	result := comicsPage{Comics: make([]comicSummary, 0, len(items)), Next: next}
+
+	for _, xkcd := range items {
+		summary := comicSummary{Number: xkcd.Number, Title: xkcd.Title, ImageURL: xkcd.ImageURL, ImageAlt: xkcd.ImageAlt}
+
+		if date, err := xkcd.Date(); err == nil {
+			summary.Date = date.Format(comic.DateLayout)
+		}
+
+		result.Comics = append(result.Comics, summary)
+	}
+
+	writeJSON(w, result)
+}
+
+// thumbHandler returns the thumbnail of a comic at /thumbs/<size>/<number>.png. The size is the longer
+// side of the thumbnail, one of config.ThumbSizes; other sizes up to config.MaxThumbSize are redirected
+// to the next larger one.
+func (s *Server) thumbHandler(w http.ResponseWriter, r *http.Request) {
+	var size, comicNum int
+
+	path := strings.TrimPrefix(r.URL.Path, "/thumbs/")
+
+	if _, err := fmt.Sscanf(path, "%d/%d.png", &size, &comicNum); err != nil || path != fmt.Sprintf("%d/%d.png", size, comicNum) {
+		http.NotFound(w, r)
+		return
+	}
+
+	if size < 1 || size > config.MaxThumbSize {
+		http.Error(w, fmt.Sprintf("invalid size: %d", size), http.StatusBadRequest)
+		return
+	}
+
+	if bucket := thumbSize(size); bucket != size {
+		http.Redirect(w, r, fmt.Sprintf("/thumbs/%d/%d.png", bucket, comicNum), http.StatusFound)
+		return
+	}
+
+	_, xkcd := s.comics.Get(comicNum)
+
+	if xkcd == nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	data, err := s.thumbs.Get(size, comicNum, xkcd.ImageData)
+
+	if err == comic.ErrNoImage {
+		http.NotFound(w, r)
+		return
+	}
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "image/png")
+	w.Write(data)
+}
+
+// thumbSize rounds size up to one of config.ThumbSizes.
+func thumbSize(size int) int {
+	for _, bucket := range config.ThumbSizes {
+		if size <= bucket {
+			return bucket
+		}
+	}
+
+	return config.MaxThumbSize
+}
+
+var playlistsTemplate = template.Must(template.New("playlists").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head><meta charset="utf-8"><title>Playlists</title></head>
+<body style="font-family: sans-serif; max-width: 960px; margin: 0 auto; padding: 1em;">
+<h1>Playlists</h1>
+<ul>
+{{range .}}<li><a href="{{.}}">{{.}}</a> (<a href="{{.}}.json">JSON</a>)</li>
+{{else}}<li>There are no playlists, create one with the playlist command.</li>
+{{end}}</ul>
+</body>
+</html>
+`))
+
+// playlistHandler lists the playlists at /playlists/ and renders a playlist at /playlists/<name> as HTML,
+// or as JSON at /playlists/<name>.json. The comics link to the pages of the generated site.
+func (s *Server) playlistHandler(w http.ResponseWriter, r *http.Request) {
+	name := strings.TrimPrefix(r.URL.Path, "/playlists/")
+
+	if name == "" {
+		names, err := s.playlists.List()
+
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		playlistsTemplate.Execute(w, names)
+
+		return
+	}
+
+	format := playlist.HTML
+
+	if strings.HasSuffix(name, ".json") {
+		name, format = strings.TrimSuffix(name, ".json"), playlist.JSON
+	}
+
+	p, err := s.playlists.Read(name)
+
+	if err == playlist.ErrNotFound {
+		http.NotFound(w, r)
+		return
+	}
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	entries := playlist.Resolve(p, func(comicNum int) *comic.XKCD {
+		_, xkcd := s.comics.Get(comicNum)
+		return xkcd
+	})
+
+	links := playlist.Links{
+		Comic: func(comicNum int) string { return "/" + site.ComicPath(comicNum) },
+		Image: func(xkcd *comic.XKCD) string {
+			if xkcd.Image == "" {
+				return xkcd.ImageURL
+			}
+
+			return fmt.Sprintf("/thumbs/%d/%d.png", playlistImageSize, xkcd.Number)
+		},
+	}
+
+	if format == playlist.JSON {
+		w.Header().Set("Content-Type", "application/json")
+		err = playlist.Write(w, format, p, entries, links)
+	} else {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		err = playlist.WriteHTML(w, p, entries, links, "/playlists/")
+	}
+
+	if err != nil {
+		logger.Info(fmt.Sprintf("playlist %s: %v", name, err))
+	}
+}
+
+// writeJSON writes value as the JSON response.
+func writeJSON(w http.ResponseWriter, value interface{}) {
+	data, err := json.Marshal(value)
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(data)
+}
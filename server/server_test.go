@@ -0,0 +1,198 @@
+package server
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"image"
+	"image/png"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"xkcd2/comic"
+	"xkcd2/playlist"
+	"xkcd2/tools/imaging"
+)
+
+func TestFeedHandler(t *testing.T) {
+	c := &comic.Comics{}
+	c.Load([]comic.XKCD{{Number: 1, Title: "First"}, {Number: 2, Title: "Second"}})
+
+	s := New(c, t.TempDir(), imaging.NewThumbCache(t.TempDir()), playlist.NewStore(t.TempDir()))
+
+	rec := httptest.NewRecorder()
+	s.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/rss.xml?n=1", nil))
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected %d, got %d", http.StatusOK, rec.Code)
+	}
+
+	body := rec.Body.String()
+
+	if !strings.Contains(body, "Second") || strings.Contains(body, "First") {
+		t.Errorf("expected only the latest comic, got %s", body)
+	}
+}
+
+func TestFeedHandlerBadCount(t *testing.T) {
+	s := New(&comic.Comics{}, t.TempDir(), imaging.NewThumbCache(t.TempDir()), playlist.NewStore(t.TempDir()))
+
+	rec := httptest.NewRecorder()
+	s.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/atom.xml?n=x", nil))
+
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("expected %d, got %d", http.StatusBadRequest, rec.Code)
+	}
+}
+
+func TestComicsHandler(t *testing.T) {
+	c := &comic.Comics{}
+	c.Load([]comic.XKCD{{Number: 1, Title: "B"}, {Number: 2, Title: "A"}, {Number: 3, Title: "C"}})
+
+	s := New(c, t.TempDir(), imaging.NewThumbCache(t.TempDir()), playlist.NewStore(t.TempDir()))
+
+	rec := httptest.NewRecorder()
+	s.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/comics.json?order=title&size=2", nil))
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected %d, got %d", http.StatusOK, rec.Code)
+	}
+
+	var got comicsPage
+
+	if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+		t.Fatalf("expected valid JSON, got %v", err)
+	}
+
+	if len(got.Comics) != 2 || got.Comics[0].Number != 2 || got.Next == "" {
+		t.Errorf("unexpected page %+v", got)
+	}
+}
+
+func TestComicsHandlerSearch(t *testing.T) {
+	c := &comic.Comics{}
+	c.Load([]comic.XKCD{{Number: 1, Title: "Barrel"}, {Number: 2, Title: "Petit Trees"}, {Number: 3, Title: "Island"}})
+
+	s := New(c, t.TempDir(), imaging.NewThumbCache(t.TempDir()), playlist.NewStore(t.TempDir()))
+
+	rec := httptest.NewRecorder()
+	s.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/comics.json?q=title:trees+OR+barrel", nil))
+
+	var got comicsPage
+
+	if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+		t.Fatalf("expected valid JSON, got %v", err)
+	}
+
+	if len(got.Comics) != 2 || got.Comics[0].Number != 1 || got.Comics[1].Number != 2 {
+		t.Errorf("unexpected page %+v", got)
+	}
+
+	rec = httptest.NewRecorder()
+	s.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/comics.json?q=num:x", nil))
+
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("expected %d, got %d", http.StatusBadRequest, rec.Code)
+	}
+}
+
+func TestThumbHandler(t *testing.T) {
+	var buf bytes.Buffer
+
+	if err := png.Encode(&buf, image.NewGray(image.Rect(0, 0, 400, 200))); err != nil {
+		t.Fatal(err)
+	}
+
+	c := &comic.Comics{}
+	c.Load([]comic.XKCD{{Number: 1, Image: base64.StdEncoding.EncodeToString(buf.Bytes())}, {Number: 2}})
+
+	thumbs := imaging.NewThumbCache(t.TempDir())
+	s := New(c, t.TempDir(), thumbs, playlist.NewStore(t.TempDir()))
+
+	rec := httptest.NewRecorder()
+	s.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/thumbs/150/1.png", nil))
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected %d, got %d", http.StatusOK, rec.Code)
+	}
+
+	config, err := png.DecodeConfig(rec.Body)
+
+	if err != nil || config.Width != 150 || config.Height != 75 {
+		t.Errorf("expected a 150x75 thumbnail, got %+v, %v", config, err)
+	}
+
+	if _, err := os.Stat(thumbs.Path(150, 1)); err != nil {
+		t.Errorf("expected the thumbnail to be cached, got %v", err)
+	}
+
+	for path, code := range map[string]int{
+		"/thumbs/150/2.png":  http.StatusNotFound,
+		"/thumbs/150/3.png":  http.StatusNotFound,
+		"/thumbs/0/1.png":    http.StatusBadRequest,
+		"/thumbs/2000/1.png": http.StatusBadRequest,
+		"/thumbs/150/1.jpeg": http.StatusNotFound,
+	} {
+		rec := httptest.NewRecorder()
+		s.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+
+		if rec.Code != code {
+			t.Errorf("%s: expected %d, got %d", path, code, rec.Code)
+		}
+	}
+}
+
+func TestThumbHandlerRoundsSize(t *testing.T) {
+	s := New(&comic.Comics{}, t.TempDir(), imaging.NewThumbCache(t.TempDir()), playlist.NewStore(t.TempDir()))
+
+	for path, want := range map[string]string{
+		"/thumbs/100/1.png":  "/thumbs/150/1.png",
+		"/thumbs/1/1.png":    "/thumbs/75/1.png",
+		"/thumbs/1000/1.png": "/thumbs/1024/1.png",
+	} {
+		rec := httptest.NewRecorder()
+		s.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+
+		if rec.Code != http.StatusFound || rec.Header().Get("Location") != want {
+			t.Errorf("%s: expected a redirect to %s, got %d %s", path, want, rec.Code, rec.Header().Get("Location"))
+		}
+	}
+}
+
+func TestPlaylistHandler(t *testing.T) {
+	c := &comic.Comics{}
+	c.Load([]comic.XKCD{{Number: 1, Title: "First", ImageURL: "https://imgs.xkcd.com/comics/first.png"}})
+
+	playlists := playlist.NewStore(t.TempDir())
+	p, _ := playlist.New("git jokes", "")
+	p.Add(0, 1, 5)
+
+	if err := playlists.Write(p); err != nil {
+		t.Fatal(err)
+	}
+
+	s := New(c, t.TempDir(), imaging.NewThumbCache(t.TempDir()), playlists)
+
+	tests := []struct {
+		path, contains string
+		code           int
+	}{
+		{"/playlists/", `href="git%20jokes"`, http.StatusOK},
+		{"/playlists/git%20jokes", `href="/comics/1.html">1: First</a>`, http.StatusOK},
+		{"/playlists/git%20jokes", "5: not in the index", http.StatusOK},
+		{"/playlists/git%20jokes.json", `"found": false`, http.StatusOK},
+		{"/playlists/other", "", http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		rec := httptest.NewRecorder()
+		s.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tt.path, nil))
+
+		if rec.Code != tt.code || !strings.Contains(rec.Body.String(), tt.contains) {
+			t.Errorf("%s: expected %d with %q, got %d: %s", tt.path, tt.code, tt.contains, rec.Code, rec.Body.String())
+		}
+	}
+}
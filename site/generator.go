@@ -0,0 +1,325 @@
+// Package site renders a collection of XKCD comics into a self-contained static web site.
+// The site uses relative links only, so it can be browsed offline straight from the disk or
+// copied to any web server.
+package site
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+
+	"xkcd2/comic"
+	"xkcd2/tools/logger"
+)
+
+const (
+	comicsFolder = "comics"
+	imagesFolder = "images"
+	assetsFolder = "assets"
+)
+
+// page is the data passed to every template. Root is the relative path from the page to the site root.
+type page struct {
+	Root  string
+	Title string
+}
+
+// comicPage holds the data of a single comic page. Navigation numbers are 0 when there is no such comic.
+type comicPage struct {
+	page
+	Comic  comic.XKCD
+	Image  string
+	Panels [][]transcriptLine
+
+	First, Prev, Next, Last int
+}
+
+// transcriptLine is a line of the parsed transcript, Class is the CSS class of its kind.
+type transcriptLine struct {
+	Class   string
+	Speaker string
+	Text    string
+}
+
+// lineClasses are the CSS classes of the transcript line kinds.
+var lineClasses = map[comic.LineKind]string{
+	comic.TextLine:     "text",
+	comic.SceneLine:    "scene",
+	comic.DialogueLine: "dialogue",
+	comic.NoteLine:     "note",
+}
+
+// archivePage lists all the comics grouped by the year of publication.
+type archivePage struct {
+	page
+	Years []archiveYear
+}
+
+type archiveYear struct {
+	Year   string
+	Comics []comic.XKCD
+}
+
+// indexPage is the landing page of the site which redirects to the latest comic.
+type indexPage struct {
+	page
+	Latest int
+}
+
+// searchEntry is a single record of the client-side search index. Short keys keep the index small.
+type searchEntry struct {
+	Number     int    `json:"n"`
+	Title      string `json:"t"`
+	Alt        string `json:"a"`
+	Transcript string `json:"s"`
+}
+
+// Options change the content of the generated site.
+type Options struct {
+	// Asset is the role of the comic asset shown on the pages instead of the image, for example comic.Asset2x.
+	// The comics without a stored asset of the role show the image.
+	Asset string
+
+	// ReadBlob returns the content of a stored asset, it is required with Asset.
+	ReadBlob func(ref string) ([]byte, error)
+}
+
+// Generate writes the static site for comics into outDir. Every comic gets its own page with navigation
+// to the first, previous, random, next and last comic. The site also contains an archive listing by year
+// and a search page. Images stored in XKCD.Image are written next to the pages, for the comics without
+// a stored image the page links to XKCD.ImageURL instead.
+func Generate(outDir string, comics []comic.XKCD) error {
+	return GenerateWith(outDir, comics, Options{})
+}
+
+// GenerateWith works as Generate with the options.
+func GenerateWith(outDir string, comics []comic.XKCD, options Options) error {
+	defer logger.Trace(fmt.Sprintf("func Generate(%s)", outDir))()
+
+	if options.Asset != "" && options.ReadBlob == nil {
+		return fmt.Errorf("site: reading the %s assets requires ReadBlob", options.Asset)
+	}
+
+	sorted := comic.NewQuery(comics).All()
+
+	for _, dir := range []string{comicsFolder, imagesFolder, assetsFolder} {
+		if err := os.MkdirAll(filepath.Join(outDir, dir), 0755); err != nil {
+			return fmt.Errorf("site: %v", err)
+		}
+	}
+
+	if err := writeAssets(outDir, sorted); err != nil {
+		return err
+	}
+
+	for i := range sorted {
+		if err := writeComicPage(outDir, sorted, i, options); err != nil {
+			return err
+		}
+	}
+
+	if err := writeArchive(outDir, sorted); err != nil {
+		return err
+	}
+
+	if err := writeSearch(outDir, sorted); err != nil {
+		return err
+	}
+
+	latest := 0
+
+	if len(sorted) > 0 {
+		latest = sorted[len(sorted)-1].Number
+	}
+
+	return execute(filepath.Join(outDir, "index.html"), "index", indexPage{page{"", "XKCD"}, latest})
+}
+
+// ComicPath returns the location of the comic page relative to the site root.
+func ComicPath(comicNum int) string {
+	return fmt.Sprintf("%s/%d.html", comicsFolder, comicNum)
+}
+
+// writeAssets writes the style sheet and the scripts shared by all the pages.
+func writeAssets(outDir string, comics []comic.XKCD) error {
+	numbers := make([]int, 0, len(comics))
+
+	for _, xkcd := range comics {
+		numbers = append(numbers, xkcd.Number)
+	}
+
+	data, err := json.Marshal(numbers)
+
+	if err != nil {
+		return fmt.Errorf("site numbers: %v", err)
+	}
+
+	files := map[string][]byte{
+		"style.css": []byte(styleCSS),
+		"site.js":   []byte(siteJS),
+		"comics.js": []byte(fmt.Sprintf("var comicNumbers = %s;\n", data)),
+		"search.js": []byte(searchJS),
+	}
+
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(outDir, assetsFolder, name), content, 0644); err != nil {
+			return fmt.Errorf("site: %v", err)
+		}
+	}
+
+	return nil
+}
+
+// writeComicPage renders the page of comics[i] together with its image.
+func writeComicPage(outDir string, comics []comic.XKCD, i int, options Options) error {
+	xkcd := comics[i]
+
+	p := comicPage{
+		page:  page{"../", fmt.Sprintf("%d: %s", xkcd.Number, xkcd.Title)},
+		Comic: xkcd,
+		First: comics[0].Number,
+		Last:  comics[len(comics)-1].Number,
+	}
+
+	if i > 0 {
+		p.Prev = comics[i-1].Number
+	}
+
+	if i < len(comics)-1 {
+		p.Next = comics[i+1].Number
+	}
+
+	image, err := writeImage(outDir, xkcd, options)
+
+	if err != nil {
+		return err
+	}
+
+	p.Image = image
+
+	for _, panel := range xkcd.ParsedTranscript().Panels {
+		lines := make([]transcriptLine, 0, len(panel.Lines))
+
+		for _, line := range panel.Lines {
+			lines = append(lines, transcriptLine{lineClasses[line.Kind], line.Speaker, line.Text})
+		}
+
+		p.Panels = append(p.Panels, lines)
+	}
+
+	return execute(filepath.Join(outDir, ComicPath(xkcd.Number)), "comic", p)
+}
+
+// writeImage decodes the stored image of xkcd into the images folder and returns its location relative
+// to a comic page. If the comic has no stored image, the remote ImageURL is returned. The stored asset
+// selected by the options is written instead of the image when the comic has one.
+func writeImage(outDir string, xkcd comic.XKCD, options Options) (string, error) {
+	if asset, ok := xkcd.Asset(options.Asset); ok && options.Asset != comic.AssetImage && asset.Blob != "" {
+		data, err := options.ReadBlob(asset.Blob)
+
+		if err != nil {
+			return "", fmt.Errorf("site asset %d: %v", xkcd.Number, err)
+		}
+
+		name := fmt.Sprintf("%d_%s%s", xkcd.Number, asset.Role, imageExt(asset.URL))
+
+		if err := os.WriteFile(filepath.Join(outDir, imagesFolder, name), data, 0644); err != nil {
+			return "", fmt.Errorf("site: %v", err)
+		}
+
+		return fmt.Sprintf("../%s/%s", imagesFolder, name), nil
+	}
+
+	if xkcd.Image == "" {
+		return xkcd.ImageURL, nil
+	}
+
+	data, err := base64.StdEncoding.DecodeString(xkcd.Image)
+
+	if err != nil {
+		return "", fmt.Errorf("site image %d: %v", xkcd.Number, err)
+	}
+
+	name := fmt.Sprintf("%d%s", xkcd.Number, imageExt(xkcd.ImageURL))
+
+	if err := os.WriteFile(filepath.Join(outDir, imagesFolder, name), data, 0644); err != nil {
+		return "", fmt.Errorf("site: %v", err)
+	}
+
+	return fmt.Sprintf("../%s/%s", imagesFolder, name), nil
+}
+
+// imageExt returns the extension of the image in imageURL or .png when it cannot be determined.
+func imageExt(imageURL string) string {
+	if u, err := url.Parse(imageURL); err == nil {
+		if ext := path.Ext(u.Path); ext != "" {
+			return ext
+		}
+	}
+
+	return ".png"
+}
+
+// writeArchive renders the archive page with the comics grouped by year, the latest year first.
+func writeArchive(outDir string, comics []comic.XKCD) error {
+	var years []archiveYear
+
+	for i := len(comics) - 1; i >= 0; i-- {
+		xkcd := comics[i]
+
+		if len(years) == 0 || years[len(years)-1].Year != xkcd.Year {
+			years = append(years, archiveYear{Year: xkcd.Year})
+		}
+
+		current := &years[len(years)-1]
+		current.Comics = append(current.Comics, xkcd)
+	}
+
+	return execute(filepath.Join(outDir, "archive.html"), "archive", archivePage{page{"", "Archive"}, years})
+}
+
+// writeSearch writes the search page and the search index used by it.
+func writeSearch(outDir string, comics []comic.XKCD) error {
+	entries := make([]searchEntry, 0, len(comics))
+
+	for _, xkcd := range comics {
+		entries = append(entries, searchEntry{xkcd.Number, xkcd.Title, xkcd.ImageAlt, xkcd.Transcript})
+	}
+
+	data, err := json.Marshal(entries)
+
+	if err != nil {
+		return fmt.Errorf("site search index: %v", err)
+	}
+
+	// The index is a script rather than a JSON document because browsers do not allow fetching
+	// local files when the site is opened from the disk.
+	content := []byte(fmt.Sprintf("var searchIndex = %s;\n", data))
+
+	if err := os.WriteFile(filepath.Join(outDir, assetsFolder, "search-index.js"), content, 0644); err != nil {
+		return fmt.Errorf("site: %v", err)
+	}
+
+	return execute(filepath.Join(outDir, "search.html"), "search", page{"", "Search"})
+}
+
+// execute renders the named template with data into filename.
+func execute(filename string, name string, data interface{}) error {
+	file, err := os.Create(filename)
+
+	if err != nil {
+		return fmt.Errorf("site: %v", err)
+	}
+
+	defer file.Close()
+
+	if err := templates.ExecuteTemplate(file, name, data); err != nil {
+		return fmt.Errorf("site template %s: %v", name, err)
+	}
+
+	return nil
+}
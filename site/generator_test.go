@@ -0,0 +1,117 @@
+package site
+
+import (
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"xkcd2/comic"
+)
+
+func setupComics() []comic.XKCD {
+	return []comic.XKCD{
+		{Number: 3, Title: "Third", Year: "2007", Month: "1", Day: "3", ImageURL: "https://imgs.xkcd.com/comics/third.jpg"},
+		{Number: 1, Title: "First", Year: "2006", Month: "1", Day: "1", ImageAlt: "first alt", ImageURL: "https://imgs.xkcd.com/comics/first.png",
+			Image: base64.StdEncoding.EncodeToString([]byte("png data"))},
+		{Number: 2, Title: "Second", Year: "2006", Month: "1", Day: "2", Transcript: "[[A man]]"},
+	}
+}
+
+func readFile(t *testing.T, name string) string {
+	t.Helper()
+
+	data, err := os.ReadFile(name)
+
+	if err != nil {
+		t.Fatalf("expected %s to exist, got %v", name, err)
+	}
+
+	return string(data)
+}
+
+func TestGenerate(t *testing.T) {
+	dir := t.TempDir()
+
+	if err := Generate(dir, setupComics()); err != nil {
+		t.Fatalf("expected err to be nil, got %v", err)
+	}
+
+	for _, name := range []string{"index.html", "archive.html", "search.html", "assets/search-index.js", "assets/comics.js"} {
+		readFile(t, filepath.Join(dir, name))
+	}
+
+	if got := readFile(t, filepath.Join(dir, "images", "1.png")); got != "png data" {
+		t.Errorf("expected decoded image, got %q", got)
+	}
+
+	second := readFile(t, filepath.Join(dir, ComicPath(2)))
+
+	for _, want := range []string{`href="1.html">&lt; Prev`, `href="3.html">Next &gt;`, `<p class="scene">A man</p>`} {
+		if !strings.Contains(second, want) {
+			t.Errorf("expected page 2 to contain %q", want)
+		}
+	}
+
+	first := readFile(t, filepath.Join(dir, ComicPath(1)))
+
+	if !strings.Contains(first, `src="../images/1.png"`) || !strings.Contains(first, `title="first alt"`) {
+		t.Errorf("expected page 1 to use the stored image with alt tooltip")
+	}
+
+	third := readFile(t, filepath.Join(dir, ComicPath(3)))
+
+	if !strings.Contains(third, `src="https://imgs.xkcd.com/comics/third.jpg"`) {
+		t.Errorf("expected page 3 to link the remote image")
+	}
+
+	index := readFile(t, filepath.Join(dir, "assets", "search-index.js"))
+
+	if !strings.Contains(index, `"t":"Second"`) {
+		t.Errorf("expected search index to contain comic titles")
+	}
+
+	archive := readFile(t, filepath.Join(dir, "archive.html"))
+
+	if strings.Index(archive, "2007") > strings.Index(archive, "2006") {
+		t.Errorf("expected the latest year to be listed first")
+	}
+}
+
+func TestGenerateEmpty(t *testing.T) {
+	dir := t.TempDir()
+
+	if err := Generate(dir, nil); err != nil {
+		t.Fatalf("expected err to be nil, got %v", err)
+	}
+
+	if got := readFile(t, filepath.Join(dir, "index.html")); !strings.Contains(got, "empty") {
+		t.Errorf("expected empty collection message")
+	}
+}
+
+func TestGenerateWithAsset(t *testing.T) {
+	dir := t.TempDir()
+	items := setupComics()
+	items[1].Assets = []comic.Asset{{Role: comic.Asset2x, URL: "https://imgs.xkcd.com/comics/first_2x.png", Blob: "ref"}}
+
+	options := Options{Asset: comic.Asset2x, ReadBlob: func(ref string) ([]byte, error) { return []byte("2x data"), nil }}
+
+	if err := GenerateWith(dir, items, options); err != nil {
+		t.Fatalf("expected err to be nil, got %v", err)
+	}
+
+	if got := readFile(t, filepath.Join(dir, imagesFolder, "1_2x.png")); got != "2x data" {
+		t.Errorf("expected the 2x asset to be written, got %q", got)
+	}
+
+	if page := readFile(t, filepath.Join(dir, ComicPath(1))); !strings.Contains(page, "images/1_2x.png") {
+		t.Errorf("expected the page to show the 2x asset")
+	}
+
+	// comics without the asset show the image
+	if page := readFile(t, filepath.Join(dir, ComicPath(3))); !strings.Contains(page, "third.jpg") {
+		t.Errorf("expected the page of comic 3 to show the remote image")
+	}
+}
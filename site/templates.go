@@ -0,0 +1,152 @@
+package site
+
+import "html/template"
+
+var templates = template.Must(template.New("site").Parse(`
+{{define "header"}}<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+<link rel="stylesheet" href="{{.Root}}assets/style.css">
+</head>
+<body>
+<header>
+<a href="{{.Root}}index.html">Latest</a>
+<a href="{{.Root}}archive.html">Archive</a>
+<a href="{{.Root}}search.html">Search</a>
+</header>
+<main>
+{{end}}
+
+{{define "footer"}}</main>
+</body>
+</html>
+{{end}}
+
+{{define "nav"}}<nav>
+<a href="{{.First}}.html">|&lt; First</a>
+{{if .Prev}}<a href="{{.Prev}}.html">&lt; Prev</a>{{else}}<span>&lt; Prev</span>{{end}}
+<a href="#" onclick="return randomComic('')">Random</a>
+{{if .Next}}<a href="{{.Next}}.html">Next &gt;</a>{{else}}<span>Next &gt;</span>{{end}}
+<a href="{{.Last}}.html">Last &gt;|</a>
+</nav>
+{{end}}
+
+{{define "comic"}}{{template "header" .}}
+<h1>{{.Comic.Number}}: {{.Comic.Title}}</h1>
+{{template "nav" .}}
+<figure>
+<img src="{{.Image}}" alt="{{.Comic.Title}}" title="{{.Comic.ImageAlt}}">
+<figcaption>{{.Comic.ImageAlt}}</figcaption>
+</figure>
+{{template "nav" .}}
+<p class="date">Published {{.Comic.Year}}-{{.Comic.Month}}-{{.Comic.Day}}</p>
+{{if .Panels}}<h2>Transcript</h2>
+<div class="transcript">{{range .Panels}}<div class="panel">
+{{range .}}<p class="{{.Class}}">{{if .Speaker}}<b>{{.Speaker}}:</b> {{end}}{{.Text}}</p>
+{{end}}</div>{{end}}</div>{{end}}
+<script src="{{.Root}}assets/comics.js"></script>
+<script src="{{.Root}}assets/site.js"></script>
+{{template "footer" .}}{{end}}
+
+{{define "archive"}}{{template "header" .}}
+<h1>Archive</h1>
+{{range .Years}}<h2>{{.Year}}</h2>
+<ul>
+{{range .Comics}}<li><a href="comics/{{.Number}}.html">{{.Number}}: {{.Title}}</a> <span class="date">{{.Year}}-{{.Month}}-{{.Day}}</span></li>
+{{end}}</ul>
+{{else}}<p>The collection is empty.</p>
+{{end}}
+{{template "footer" .}}{{end}}
+
+{{define "search"}}{{template "header" .}}
+<h1>Search</h1>
+<form onsubmit="return false">
+<input id="query" type="search" placeholder="title, alt text or transcript" autofocus>
+</form>
+<ul id="results"></ul>
+<script src="assets/search-index.js"></script>
+<script src="assets/search.js"></script>
+{{template "footer" .}}{{end}}
+
+{{define "index"}}<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+{{if .Latest}}<meta http-equiv="refresh" content="0; url=comics/{{.Latest}}.html">{{end}}
+<link rel="stylesheet" href="assets/style.css">
+</head>
+<body>
+<main>
+{{if .Latest}}<p><a href="comics/{{.Latest}}.html">Go to the latest comic</a></p>
+{{else}}<p>The collection is empty.</p>{{end}}
+</main>
+</body>
+</html>
+{{end}}
+`))
+
+const styleCSS = `body { font-family: sans-serif; max-width: 960px; margin: 0 auto; padding: 1em; }
+header a, nav a, nav span { margin-right: 1em; }
+nav span { color: #999; }
+figure { text-align: center; margin: 1em 0; }
+figure img { max-width: 100%; }
+figcaption { font-style: italic; margin-top: .5em; }
+.date { color: #666; }
+.transcript { background: #f5f5f5; padding: 1em; }
+.transcript .panel + .panel { border-top: 1px solid #ddd; }
+.transcript p { margin: .3em 0; }
+.transcript .scene { font-style: italic; color: #555; }
+.transcript .note { font-size: .9em; color: #888; }
+#query { width: 100%; font-size: 1.2em; }
+`
+
+const siteJS = `function randomComic(prefix) {
+	if (typeof comicNumbers === "undefined" || comicNumbers.length === 0) {
+		return false;
+	}
+	var n = comicNumbers[Math.floor(Math.random() * comicNumbers.length)];
+	window.location.href = prefix + n + ".html";
+	return false;
+}
+`
+
+const searchJS = `(function () {
+	var input = document.getElementById("query");
+	var results = document.getElementById("results");
+
+	function matches(entry, words) {
+		var text = (entry.n + " " + entry.t + " " + entry.a + " " + entry.s).toLowerCase();
+		for (var i = 0; i < words.length; i++) {
+			if (text.indexOf(words[i]) < 0) {
+				return false;
+			}
+		}
+		return true;
+	}
+
+	function search() {
+		var words = input.value.toLowerCase().split(/\s+/).filter(function (w) { return w.length > 0; });
+		results.innerHTML = "";
+		if (words.length === 0) {
+			return;
+		}
+		for (var i = searchIndex.length - 1; i >= 0; i--) {
+			var entry = searchIndex[i];
+			if (!matches(entry, words)) {
+				continue;
+			}
+			var link = document.createElement("a");
+			link.href = "comics/" + entry.n + ".html";
+			link.textContent = entry.n + ": " + entry.t;
+			var item = document.createElement("li");
+			item.appendChild(link);
+			results.appendChild(item);
+		}
+	}
+
+	input.addEventListener("input", search);
+})();
+`
@@ -0,0 +1,304 @@
+// Package stats builds a statistics report about the offline collection and the index file.
+package stats
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"xkcd2/comic"
+	"xkcd2/tools/imaging"
+	"xkcd2/tools/logger"
+)
+
+// TopWordsCount is the number of most frequent words included in a report.
+const TopWordsCount = 10
+
+// Report holds the statistics of a collection. Dates are formatted using comic.DateLayout.
+type Report struct {
+	Total     int    `json:"total"`
+	First     int    `json:"first"`
+	Last      int    `json:"last"`
+	FirstDate string `json:"first_date,omitempty"`
+	LastDate  string `json:"last_date,omitempty"`
+	Missing   []int  `json:"missing"` // gaps between 1 and Last, see comic.MissingNumbers
+
+	PerYear map[string]int `json:"per_year"`
+
+	ImagesStored  int   `json:"images_stored"`
+	ImagesMissing int   `json:"images_missing"`
+	ImagesSize    int64 `json:"images_size"`
+
+	// counts of the analyzed images, see comic.XKCD.Metadata
+	ImagesAnalyzed  int            `json:"images_analyzed"`
+	ImagesAnimated  int            `json:"images_animated"`
+	ImagesColor     int            `json:"images_color"`
+	ImagesOversized int            `json:"images_oversized"`
+	ImageFormats    map[string]int `json:"image_formats,omitempty"`
+
+	IndexSize int64      `json:"index_size"`
+	LastSync  *time.Time `json:"last_sync,omitempty"`
+
+	AvgTitleLength      float64     `json:"avg_title_length"`
+	AvgTranscriptLength float64     `json:"avg_transcript_length"`
+	TopWords            []WordCount `json:"top_words"`
+}
+
+// WordCount is the number of occurrences of a word in titles, alt texts and transcripts.
+type WordCount struct {
+	Word  string `json:"word"`
+	Count int    `json:"count"`
+}
+
+// stopWords are excluded from the most frequent words.
+var stopWords = map[string]bool{
+	"the": true, "and": true, "you": true, "that": true, "this": true, "for": true, "with": true,
+	"are": true, "was": true, "but": true, "not": true, "have": true, "they": true, "its": true,
+	"it's": true, "what": true, "can": true, "all": true, "just": true, "from": true, "your": true,
+	"there": true, "about": true, "one": true, "out": true, "will": true, "like": true, "i'm": true,
+	"don't": true, "his": true, "her": true, "has": true, "who": true, "would": true, "when": true,
+	"text": true, "title": true, "alt": true,
+}
+
+// Build creates the report for comics. The size of indexFile is used as the index size; if the file cannot
+// be read, it is left empty. lastSync is the time of the last sync, nil if it is not known.
+func Build(comics []comic.XKCD, indexFile string, lastSync *time.Time) *Report {
+	defer logger.Trace("func Build")()
+
+	r := &Report{Total: len(comics), PerYear: make(map[string]int), Missing: comic.MissingNumbers(comics, 0), LastSync: lastSync}
+
+	if info, err := os.Stat(indexFile); err == nil {
+		r.IndexSize = info.Size()
+	}
+
+	if len(comics) == 0 {
+		return r
+	}
+
+	sorted := comic.NewQuery(comics).All()
+
+	r.First = sorted[0].Number
+	r.Last = sorted[len(sorted)-1].Number
+	r.FirstDate = formatDate(&sorted[0])
+	r.LastDate = formatDate(&sorted[len(sorted)-1])
+
+	var titles, transcripts int
+	words := make(map[string]int)
+
+	for _, xkcd := range sorted {
+		if date, err := xkcd.Date(); err == nil {
+			r.PerYear[fmt.Sprint(date.Year())]++
+		}
+
+		if xkcd.Image != "" {
+			r.ImagesStored++
+			r.ImagesSize += decodedSize(xkcd.Image)
+		} else {
+			r.ImagesMissing++
+		}
+
+		if xkcd.Analyzed() {
+			r.countMetadata(xkcd.Metadata)
+		}
+
+		titles += utf8.RuneCountInString(xkcd.Title)
+		transcripts += utf8.RuneCountInString(xkcd.Transcript)
+
+		countWords(words, xkcd.Title, xkcd.ImageAlt, xkcd.Transcript)
+	}
+
+	r.AvgTitleLength = float64(titles) / float64(len(sorted))
+	r.AvgTranscriptLength = float64(transcripts) / float64(len(sorted))
+	r.TopWords = topWords(words, TopWordsCount)
+
+	return r
+}
+
+// countMetadata adds an analyzed image to the image counts.
+func (r *Report) countMetadata(metadata imaging.Metadata) {
+	if r.ImageFormats == nil {
+		r.ImageFormats = make(map[string]int)
+	}
+
+	r.ImagesAnalyzed++
+	r.ImageFormats[metadata.Format]++
+
+	if metadata.Animated() {
+		r.ImagesAnimated++
+	}
+
+	if metadata.Color() {
+		r.ImagesColor++
+	}
+
+	if comic.Oversized(metadata) {
+		r.ImagesOversized++
+	}
+}
+
+// WriteJSON writes the report as an indented JSON document.
+func (r *Report) WriteJSON(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+
+	if err := encoder.Encode(r); err != nil {
+		return fmt.Errorf("stats json: %v", err)
+	}
+
+	return nil
+}
+
+// WriteText writes the report as human readable text.
+func (r *Report) WriteText(w io.Writer) error {
+	var sb strings.Builder
+
+	fmt.Fprintf(&sb, "Index status: %d\n", r.Total)
+
+	if r.Total > 0 {
+		fmt.Fprintf(&sb, "Range: %d - %d (%s - %s)\n", r.First, r.Last, r.FirstDate, r.LastDate)
+	}
+
+	fmt.Fprintf(&sb, "Missing: %d", len(r.Missing))
+
+	if len(r.Missing) > 0 {
+		fmt.Fprintf(&sb, " %v", r.Missing)
+	}
+
+	fmt.Fprintf(&sb, "\nImages: %d stored, %d missing, %s\n", r.ImagesStored, r.ImagesMissing, formatSize(r.ImagesSize))
+	if r.ImagesAnalyzed > 0 {
+		formats := make([]string, 0, len(r.ImageFormats))
+
+		for format, count := range r.ImageFormats {
+			formats = append(formats, fmt.Sprintf("%s %d", format, count))
+		}
+
+		sort.Strings(formats)
+
+		fmt.Fprintf(&sb, "Analyzed images: %d (%s), %d animated, %d color, %d oversized\n", r.ImagesAnalyzed,
+			strings.Join(formats, ", "), r.ImagesAnimated, r.ImagesColor, r.ImagesOversized)
+	}
+
+	fmt.Fprintf(&sb, "Index file: %s", formatSize(r.IndexSize))
+
+	if r.LastSync != nil {
+		fmt.Fprintf(&sb, ", last sync %s", r.LastSync.Format("2006-01-02 15:04:05"))
+	}
+
+	fmt.Fprintf(&sb, "\nAverage title length: %.1f\n", r.AvgTitleLength)
+	fmt.Fprintf(&sb, "Average transcript length: %.1f\n", r.AvgTranscriptLength)
+
+	if len(r.TopWords) > 0 {
+		fmt.Fprintf(&sb, "Most frequent words:")
+
+		for _, wc := range r.TopWords {
+			fmt.Fprintf(&sb, " %s (%d)", wc.Word, wc.Count)
+		}
+
+		fmt.Fprintln(&sb)
+	}
+
+	if len(r.PerYear) > 0 {
+		years := make([]string, 0, len(r.PerYear))
+
+		for year := range r.PerYear {
+			years = append(years, year)
+		}
+
+		sort.Strings(years)
+
+		fmt.Fprintf(&sb, "Comics per year:\n")
+
+		for _, year := range years {
+			fmt.Fprintf(&sb, "  %s: %d\n", year, r.PerYear[year])
+		}
+	}
+
+	_, err := io.WriteString(w, sb.String())
+
+	return err
+}
+
+// formatDate returns the publication date of xkcd or an empty string if it is malformed.
+func formatDate(xkcd *comic.XKCD) string {
+	date, err := xkcd.Date()
+
+	if err != nil {
+		return ""
+	}
+
+	return date.Format(comic.DateLayout)
+}
+
+// decodedSize returns the number of bytes encoded in a base64 string without decoding it.
+func decodedSize(encoded string) int64 {
+	size := int64(len(encoded)) / 4 * 3
+
+	return size - int64(len(encoded)-len(strings.TrimRight(encoded, "=")))
+}
+
+// formatSize formats size in bytes using binary units.
+func formatSize(size int64) string {
+	const unit = 1024
+
+	if size < unit {
+		return fmt.Sprintf("%d B", size)
+	}
+
+	div, exp := int64(unit), 0
+
+	for n := size / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+
+	return fmt.Sprintf("%.1f %ciB", float64(size)/float64(div), "KMGTPE"[exp])
+}
+
+// countWords adds the occurrences of the words in texts to words. Words shorter than three letters
+// and stop words are ignored.
+func countWords(words map[string]int, texts ...string) {
+	for _, text := range texts {
+		fields := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+			return !unicode.IsLetter(r) && r != '\''
+		})
+
+		for _, word := range fields {
+			word = strings.Trim(word, "'")
+
+			if utf8.RuneCountInString(word) < 3 || stopWords[word] {
+				continue
+			}
+
+			words[word]++
+		}
+	}
+}
+
+// topWords returns count most frequent words, ties are ordered alphabetically.
+func topWords(words map[string]int, count int) []WordCount {
+	result := make([]WordCount, 0, len(words))
+
+	for word, n := range words {
+		result = append(result, WordCount{word, n})
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Count != result[j].Count {
+			return result[i].Count > result[j].Count
+		}
+
+		return result[i].Word < result[j].Word
+	})
+
+	if len(result) > count {
+		result = result[:count]
+	}
+
+	return result
+}
@@ -0,0 +1,119 @@
+package stats
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"xkcd2/comic"
+	"xkcd2/tools/imaging"
+)
+
+func setupComics() []comic.XKCD {
+	return []comic.XKCD{
+		{Number: 5, Title: "Robots", Year: "2007", Month: "2", Day: "1", Transcript: "robots robots everywhere"},
+		{Number: 1, Title: "Barrel", Year: "2006", Month: "1", Day: "1", Image: base64.StdEncoding.EncodeToString([]byte("12345")),
+			Metadata: imaging.Metadata{Format: "gif", Width: 2000, Height: 300, Frames: 4}},
+		{Number: 2, Title: "Petit", Year: "2006", Month: "1", Day: "2", ImageAlt: "the robots are coming"},
+	}
+}
+
+func TestBuild(t *testing.T) {
+	indexFile := filepath.Join(t.TempDir(), "xkcd.idx")
+
+	if err := os.WriteFile(indexFile, []byte("0123456789"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	lastSync := time.Date(2021, 5, 1, 10, 0, 0, 0, time.UTC)
+	r := Build(setupComics(), indexFile, &lastSync)
+
+	if r.Total != 3 || r.First != 1 || r.Last != 5 {
+		t.Errorf("expected 3 comics in range 1-5, got %d in %d-%d", r.Total, r.First, r.Last)
+	}
+
+	if r.FirstDate != "2006-01-01" || r.LastDate != "2007-02-01" {
+		t.Errorf("unexpected date range %s - %s", r.FirstDate, r.LastDate)
+	}
+
+	if len(r.Missing) != 2 || r.Missing[0] != 3 || r.Missing[1] != 4 {
+		t.Errorf("expected missing [3 4], got %v", r.Missing)
+	}
+
+	if r.PerYear["2006"] != 2 || r.PerYear["2007"] != 1 {
+		t.Errorf("unexpected comics per year %v", r.PerYear)
+	}
+
+	if r.ImagesStored != 1 || r.ImagesMissing != 2 || r.ImagesSize != 5 {
+		t.Errorf("expected 1 stored image of 5 bytes and 2 missing, got %d, %d, %d",
+			r.ImagesStored, r.ImagesMissing, r.ImagesSize)
+	}
+
+	if r.ImagesAnalyzed != 1 || r.ImagesAnimated != 1 || r.ImagesColor != 0 || r.ImagesOversized != 1 || r.ImageFormats["gif"] != 1 {
+		t.Errorf("unexpected image metadata counts %d, %d, %d, %d, %v",
+			r.ImagesAnalyzed, r.ImagesAnimated, r.ImagesColor, r.ImagesOversized, r.ImageFormats)
+	}
+
+	if r.IndexSize != 10 || r.LastSync == nil || !r.LastSync.Equal(lastSync) {
+		t.Errorf("expected index size 10 and last sync time, got %d, %v", r.IndexSize, r.LastSync)
+	}
+
+	if len(r.TopWords) == 0 || r.TopWords[0].Word != "robots" || r.TopWords[0].Count != 4 {
+		t.Errorf("expected robots to be the most frequent word, got %v", r.TopWords)
+	}
+}
+
+func TestBuildMissing(t *testing.T) {
+	r := Build([]comic.XKCD{{Number: 3}, {Number: 403}, {Number: 405}}, "", nil)
+
+	if len(r.Missing) != 401 || r.Missing[0] != 1 || r.Missing[1] != 2 {
+		t.Fatalf("expected 401 missing comics starting with 1, 2, got %d", len(r.Missing))
+	}
+
+	for _, num := range r.Missing {
+		if num == 404 {
+			t.Errorf("expected absent comic 404 not to be reported")
+		}
+	}
+}
+
+func TestBuildEmpty(t *testing.T) {
+	r := Build(nil, filepath.Join(t.TempDir(), "missing.idx"), nil)
+
+	if r.Total != 0 || r.LastSync != nil {
+		t.Errorf("expected an empty report, got %+v", r)
+	}
+
+	var buf bytes.Buffer
+
+	if err := r.WriteText(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.HasPrefix(buf.String(), "Index status: 0") {
+		t.Errorf("unexpected text %s", buf.String())
+	}
+}
+
+func TestWriteJSON(t *testing.T) {
+	var buf bytes.Buffer
+
+	if err := Build(setupComics(), "", nil).WriteJSON(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	var got Report
+
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("expected valid JSON, got %v", err)
+	}
+
+	if got.Total != 3 || len(got.Missing) != 2 {
+		t.Errorf("unexpected report %+v", got)
+	}
+}
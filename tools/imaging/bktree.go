@@ -0,0 +1,102 @@
+package imaging
+
+import "sort"
+
+// Match is an item found in a BKTree with its distance from the searched hash.
+type Match struct {
+	ID       int
+	Distance int
+}
+
+// BKTree indexes hashes by their Hamming distance. A search visits only the subtrees that can contain
+// hashes within the distance, instead of comparing the hash with every item.
+type BKTree struct {
+	root *bkNode
+	size int
+}
+
+type bkNode struct {
+	hash     uint64
+	ids      []int
+	children map[int]*bkNode
+}
+
+// Add adds the item id with hash to the tree. Items with the same hash share a node.
+func (t *BKTree) Add(hash uint64, id int) {
+	t.size++
+
+	if t.root == nil {
+		t.root = &bkNode{hash: hash, ids: []int{id}}
+		return
+	}
+
+	node := t.root
+
+	for {
+		d := Distance(node.hash, hash)
+
+		if d == 0 {
+			node.ids = append(node.ids, id)
+			return
+		}
+
+		child, ok := node.children[d]
+
+		if !ok {
+			if node.children == nil {
+				node.children = make(map[int]*bkNode)
+			}
+
+			node.children[d] = &bkNode{hash: hash, ids: []int{id}}
+			return
+		}
+
+		node = child
+	}
+}
+
+// Len returns the number of items in the tree.
+func (t *BKTree) Len() int {
+	return t.size
+}
+
+// Search returns the items whose hash is at most maxDistance from hash, the nearest first.
+func (t *BKTree) Search(hash uint64, maxDistance int) []Match {
+	var result []Match
+
+	if t.root == nil {
+		return result
+	}
+
+	stack := []*bkNode{t.root}
+
+	for len(stack) > 0 {
+		node := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+
+		d := Distance(node.hash, hash)
+
+		if d <= maxDistance {
+			for _, id := range node.ids {
+				result = append(result, Match{ID: id, Distance: d})
+			}
+		}
+
+		// by the triangle inequality, only the children between d-maxDistance and d+maxDistance can match
+		for childDistance, child := range node.children {
+			if childDistance >= d-maxDistance && childDistance <= d+maxDistance {
+				stack = append(stack, child)
+			}
+		}
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Distance != result[j].Distance {
+			return result[i].Distance < result[j].Distance
+		}
+
+		return result[i].ID < result[j].ID
+	})
+
+	return result
+}
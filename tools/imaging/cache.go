@@ -0,0 +1,88 @@
+package imaging
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"xkcd2/tools/util"
+)
+
+// ThumbCache stores the generated thumbnails as PNG files in dir/<size>/<number>.png, so they are
+// generated only once for every size.
+type ThumbCache struct {
+	dir string
+}
+
+// NewThumbCache creates a cache of thumbnails in dir. The folders are created when the first thumbnail is written.
+func NewThumbCache(dir string) *ThumbCache {
+	return &ThumbCache{dir: dir}
+}
+
+// Path returns the filename of the thumbnail of the comic number at size.
+func (c *ThumbCache) Path(size int, number int) string {
+	return filepath.Join(c.dir, strconv.Itoa(size), fmt.Sprintf("%d.png", number))
+}
+
+// Get returns the cached thumbnail. If it is not cached yet, it decodes the image returned by source,
+// scales it and writes the thumbnail to the cache.
+func (c *ThumbCache) Get(size int, number int, source func() ([]byte, error)) ([]byte, error) {
+	filename := c.Path(size, number)
+
+	if data, err := ioutil.ReadFile(filename); err == nil {
+		return data, nil
+	}
+
+	original, err := source()
+
+	if err != nil {
+		return nil, err
+	}
+
+	img, _, err := Decode(original)
+
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := EncodePNG(Thumbnail(img, size))
+
+	if err != nil {
+		return nil, err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(filename), 0755); err != nil {
+		return nil, fmt.Errorf("thumbnail cache: %v", err)
+	}
+
+	if err := util.WriteFileAtomic(filename, data); err != nil {
+		return nil, fmt.Errorf("thumbnail cache: %v", err)
+	}
+
+	return data, nil
+}
+
+// Invalidate removes the thumbnails of the comic number in all sizes, for example after its image was replaced.
+func (c *ThumbCache) Invalidate(number int) error {
+	sizes, err := ioutil.ReadDir(c.dir)
+
+	if os.IsNotExist(err) {
+		return nil
+	}
+
+	if err != nil {
+		return fmt.Errorf("thumbnail cache: %v", err)
+	}
+
+	for _, size := range sizes {
+		filename := filepath.Join(c.dir, size.Name(), fmt.Sprintf("%d.png", number))
+
+		if err := os.Remove(filename); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("thumbnail cache: %v", err)
+		}
+	}
+
+	return nil
+}
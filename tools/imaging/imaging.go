@@ -1,6 +1,25 @@
 package imaging
 
-import "encoding/base64"
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"image"
+
+	// decoders of the image formats used by xkcd
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+)
+
+// Info describes a decoded image.
+type Info struct {
+	Format string
+	Width  int
+	Height int
+}
 
 // Converts byte slice to string
 func EncodeToBase64(image []byte) string {
@@ -8,3 +27,33 @@ func EncodeToBase64(image []byte) string {
 
 	return result
 }
+
+// DecodeFromBase64 converts a string produced by EncodeToBase64 back to a byte slice.
+func DecodeFromBase64(image string) ([]byte, error) {
+	return base64.StdEncoding.DecodeString(image)
+}
+
+// Hash returns the hex encoded SHA-256 checksum of the image.
+func Hash(image []byte) string {
+	sum := sha256.Sum256(image)
+
+	return hex.EncodeToString(sum[:])
+}
+
+// Validate decodes the whole image to make sure it is a complete PNG, JPEG or GIF image.
+// A truncated download fails to decode.
+func Validate(data []byte) (Info, error) {
+	img, format, err := image.Decode(bytes.NewReader(data))
+
+	if err != nil {
+		return Info{}, fmt.Errorf("validate: %v", err)
+	}
+
+	bounds := img.Bounds()
+
+	if bounds.Empty() {
+		return Info{}, fmt.Errorf("validate: empty %s image", format)
+	}
+
+	return Info{Format: format, Width: bounds.Dx(), Height: bounds.Dy()}, nil
+}
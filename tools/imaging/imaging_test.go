@@ -0,0 +1,186 @@
+package imaging
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/png"
+	"io/ioutil"
+	"path/filepath"
+	"sync"
+	"testing"
+)
+
+func encodePNG(t *testing.T, width, height int) []byte {
+	var buf bytes.Buffer
+
+	if err := png.Encode(&buf, image.NewGray(image.Rect(0, 0, width, height))); err != nil {
+		t.Fatal(err)
+	}
+
+	return buf.Bytes()
+}
+
+func TestValidate(t *testing.T) {
+	data := encodePNG(t, 30, 20)
+
+	info, err := Validate(data)
+
+	if err != nil {
+		t.Fatalf("expected a valid image, got %v", err)
+	}
+
+	if info != (Info{Format: "png", Width: 30, Height: 20}) {
+		t.Errorf("unexpected image info %+v", info)
+	}
+
+	if _, err := Validate(data[:len(data)/2]); err == nil {
+		t.Errorf("expected an error for a truncated image")
+	}
+}
+
+func TestThumbnail(t *testing.T) {
+	src := image.NewGray(image.Rect(0, 0, 40, 10))
+
+	// left half black, right half white
+	for x := 20; x < 40; x++ {
+		for y := 0; y < 10; y++ {
+			src.Pix[src.PixOffset(x, y)] = 255
+		}
+	}
+
+	thumb := Thumbnail(src, 8)
+
+	if thumb.Bounds().Dx() != 8 || thumb.Bounds().Dy() != 2 {
+		t.Fatalf("expected 8x2 thumbnail, got %v", thumb.Bounds())
+	}
+
+	if r, _, _, _ := thumb.At(0, 0).RGBA(); r != 0 {
+		t.Errorf("expected a black pixel on the left, got %d", r)
+	}
+
+	if r, _, _, _ := thumb.At(7, 1).RGBA(); r != 0xffff {
+		t.Errorf("expected a white pixel on the right, got %d", r)
+	}
+
+	if small := Thumbnail(src, 100); small.Bounds().Dx() != 40 {
+		t.Errorf("expected a small image not to be scaled up, got %v", small.Bounds())
+	}
+}
+
+func TestThumbCache(t *testing.T) {
+	cache := NewThumbCache(t.TempDir())
+	calls := 0
+	source := func() ([]byte, error) {
+		calls++
+		return encodePNG(t, 30, 60), nil
+	}
+
+	for i := 0; i < 2; i++ {
+		if _, err := cache.Get(20, 1, source); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if calls != 1 {
+		t.Errorf("expected the source to be read once, got %d", calls)
+	}
+
+	if err := cache.Invalidate(1); err != nil {
+		t.Fatal(err)
+	}
+
+	cache.Get(20, 1, source)
+
+	if calls != 2 {
+		t.Errorf("expected the thumbnail to be generated again after Invalidate, got %d calls", calls)
+	}
+}
+
+func TestThumbCacheConcurrentWrites(t *testing.T) {
+	dir := t.TempDir()
+	cache := NewThumbCache(dir)
+	original := encodePNG(t, 30, 60)
+	source := func() ([]byte, error) { return original, nil }
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 10)
+
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			if _, err := cache.Get(20, 1, source); err != nil {
+				errs <- err
+			}
+		}()
+	}
+
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		t.Error(err)
+	}
+
+	files, err := ioutil.ReadDir(filepath.Join(dir, "20"))
+
+	if err != nil || len(files) != 1 {
+		t.Fatalf("expected only the thumbnail without temporary files, got %v, %v", files, err)
+	}
+
+	data, err := ioutil.ReadFile(cache.Path(20, 1))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, _, err := Decode(data); err != nil {
+		t.Errorf("expected a valid thumbnail, got %v", err)
+	}
+}
+
+func TestAnalyze(t *testing.T) {
+	gray, err := Analyze(encodePNG(t, 30, 20))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if gray.Format != "png" || gray.Width != 30 || gray.Height != 20 || gray.Frames != 1 || gray.Color() {
+		t.Errorf("unexpected metadata of a gray image %+v", gray)
+	}
+
+	palette := color.Palette{color.Black, color.RGBA{255, 0, 0, 255}}
+	animation := &gif.GIF{}
+
+	for i := 0; i < 3; i++ {
+		frame := image.NewPaletted(image.Rect(0, 0, 10, 10), palette)
+
+		for p := range frame.Pix {
+			frame.Pix[p] = uint8((p + i) % 2)
+		}
+
+		animation.Image = append(animation.Image, frame)
+		animation.Delay = append(animation.Delay, 10)
+	}
+
+	var buf bytes.Buffer
+
+	if err := gif.EncodeAll(&buf, animation); err != nil {
+		t.Fatal(err)
+	}
+
+	colored, err := Analyze(buf.Bytes())
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if colored.Format != "gif" || colored.Frames != 3 || !colored.Animated() || !colored.Color() {
+		t.Errorf("unexpected metadata of an animated color image %+v", colored)
+	}
+}
@@ -0,0 +1,97 @@
+package imaging
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/draw"
+	"image/gif"
+	"math"
+)
+
+// ColorThreshold is the colorfulness from which an image is considered a color image. Black and white
+// images have a colorfulness of 0, scanned or anti-aliased ones stay well below the threshold.
+const ColorThreshold = 10
+
+// Metadata describes a stored image.
+type Metadata struct {
+	Format       string  // png, jpeg or gif
+	Width        int     // in pixels, of the first frame for an animated image
+	Height       int     // in pixels, of the first frame for an animated image
+	Frames       int     // number of frames, greater than 1 for an animated GIF
+	Colorfulness float64 // colorfulness metric of Hasler and Süsstrunk, 0 for grayscale images
+}
+
+// Animated returns true if the image has more than one frame.
+func (m Metadata) Animated() bool {
+	return m.Frames > 1
+}
+
+// Color returns true if the colorfulness reaches ColorThreshold.
+func (m Metadata) Color() bool {
+	return m.Colorfulness >= ColorThreshold
+}
+
+// Analyze decodes the image and returns its metadata.
+func Analyze(data []byte) (Metadata, error) {
+	img, format, err := Decode(data)
+
+	if err != nil {
+		return Metadata{}, err
+	}
+
+	return Describe(img, format, data)
+}
+
+// Describe returns the metadata of img already decoded from data by Decode.
+func Describe(img image.Image, format string, data []byte) (Metadata, error) {
+	bounds := img.Bounds()
+	result := Metadata{Format: format, Width: bounds.Dx(), Height: bounds.Dy(), Frames: 1}
+
+	if format == "gif" {
+		animation, err := gif.DecodeAll(bytes.NewReader(data))
+
+		if err != nil {
+			return Metadata{}, fmt.Errorf("analyze: %v", err)
+		}
+
+		result.Frames = len(animation.Image)
+	}
+
+	result.Colorfulness = Colorfulness(img)
+
+	return result, nil
+}
+
+// Colorfulness returns the colorfulness metric of Hasler and Süsstrunk, computed from the mean and
+// the standard deviation of the opponent color components rg = R - G and yb = (R + G) / 2 - B.
+func Colorfulness(img image.Image) float64 {
+	bounds := img.Bounds()
+	rgba := image.NewRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	draw.Draw(rgba, rgba.Bounds(), img, bounds.Min, draw.Src)
+
+	count := float64(bounds.Dx() * bounds.Dy())
+
+	if count == 0 {
+		return 0
+	}
+
+	var sumRG, sumYB, sumRG2, sumYB2 float64
+
+	for i := 0; i < len(rgba.Pix); i += 4 {
+		r, g, b := float64(rgba.Pix[i]), float64(rgba.Pix[i+1]), float64(rgba.Pix[i+2])
+		rg := r - g
+		yb := (r+g)/2 - b
+
+		sumRG += rg
+		sumYB += yb
+		sumRG2 += rg * rg
+		sumYB2 += yb * yb
+	}
+
+	meanRG, meanYB := sumRG/count, sumYB/count
+	varRG := math.Max(sumRG2/count-meanRG*meanRG, 0)
+	varYB := math.Max(sumYB2/count-meanYB*meanYB, 0)
+
+	return math.Sqrt(varRG+varYB) + 0.3*math.Sqrt(meanRG*meanRG+meanYB*meanYB)
+}
@@ -0,0 +1,156 @@
+package imaging
+
+import (
+	"image"
+	"image/draw"
+	"math"
+	"math/bits"
+	"sort"
+)
+
+// Hashes are the perceptual hashes of an image. Similar images have hashes with a small Hamming distance.
+type Hashes struct {
+	AHash uint64 // average hash, 8x8 pixels compared with their mean
+	DHash uint64 // difference hash, 9x8 pixels compared with their right neighbour
+	PHash uint64 // DCT hash, the lowest 8x8 frequencies of 32x32 pixels compared with their median
+}
+
+// ComputeHashes returns the perceptual hashes of img.
+func ComputeHashes(img image.Image) Hashes {
+	return Hashes{AHash: AHash(img), DHash: DHash(img), PHash: PHash(img)}
+}
+
+// Get returns the hash of the kind "a", "d" or "p".
+func (h Hashes) Get(kind string) (uint64, bool) {
+	switch kind {
+	case "a":
+		return h.AHash, true
+	case "d":
+		return h.DHash, true
+	case "p":
+		return h.PHash, true
+	}
+
+	return 0, false
+}
+
+// Distance returns the Hamming distance between two hashes, the number of bits they differ in.
+func Distance(a, b uint64) int {
+	return bits.OnesCount64(a ^ b)
+}
+
+// AHash returns the average hash of img.
+func AHash(img image.Image) uint64 {
+	pixels := grayscale(img, 8, 8)
+
+	var sum float64
+
+	for _, p := range pixels {
+		sum += p
+	}
+
+	mean := sum / float64(len(pixels))
+
+	return threshold(pixels, mean)
+}
+
+// DHash returns the difference hash of img.
+func DHash(img image.Image) uint64 {
+	pixels := grayscale(img, 9, 8)
+
+	var hash uint64
+
+	for y := 0; y < 8; y++ {
+		for x := 0; x < 8; x++ {
+			hash <<= 1
+
+			if pixels[y*9+x] > pixels[y*9+x+1] {
+				hash |= 1
+			}
+		}
+	}
+
+	return hash
+}
+
+// PHash returns the DCT based hash of img.
+func PHash(img image.Image) uint64 {
+	const size = 32
+
+	pixels := grayscale(img, size, size)
+	coefficients := make([]float64, 0, 64)
+
+	for v := 0; v < 8; v++ {
+		for u := 0; u < 8; u++ {
+			var sum float64
+
+			for y := 0; y < size; y++ {
+				for x := 0; x < size; x++ {
+					sum += pixels[y*size+x] *
+						math.Cos(float64(2*x+1)*float64(u)*math.Pi/(2*size)) *
+						math.Cos(float64(2*y+1)*float64(v)*math.Pi/(2*size))
+				}
+			}
+
+			coefficients = append(coefficients, sum)
+		}
+	}
+
+	// the DC coefficient is the average brightness, it would dominate the median
+	sorted := append([]float64(nil), coefficients[1:]...)
+	sort.Float64s(sorted)
+	median := (sorted[len(sorted)/2-1] + sorted[len(sorted)/2]) / 2
+
+	return threshold(coefficients, median)
+}
+
+// threshold returns a hash with a bit set for each of the 64 values greater than limit.
+func threshold(values []float64, limit float64) uint64 {
+	var hash uint64
+
+	for _, v := range values {
+		hash <<= 1
+
+		if v > limit {
+			hash |= 1
+		}
+	}
+
+	return hash
+}
+
+// grayscale scales img to width x height by averaging and returns the luminance of the pixels row by row.
+// Unlike Thumbnail, the aspect ratio is not kept.
+func grayscale(img image.Image, width, height int) []float64 {
+	bounds := img.Bounds()
+	srcWidth, srcHeight := bounds.Dx(), bounds.Dy()
+
+	gray := image.NewGray(image.Rect(0, 0, srcWidth, srcHeight))
+	draw.Draw(gray, gray.Bounds(), img, bounds.Min, draw.Src)
+
+	result := make([]float64, width*height)
+
+	if srcWidth == 0 || srcHeight == 0 {
+		return result
+	}
+
+	for y := 0; y < height; y++ {
+		y0, y1 := y*srcHeight/height, max((y+1)*srcHeight/height, y*srcHeight/height+1)
+
+		for x := 0; x < width; x++ {
+			x0, x1 := x*srcWidth/width, max((x+1)*srcWidth/width, x*srcWidth/width+1)
+
+			var sum int
+
+			for sy := y0; sy < y1; sy++ {
+				for sx := x0; sx < x1; sx++ {
+					sum += int(gray.Pix[gray.PixOffset(sx, sy)])
+				}
+			}
+
+			result[y*width+x] = float64(sum) / float64((x1-x0)*(y1-y0))
+		}
+	}
+
+	return result
+}
@@ -0,0 +1,92 @@
+package imaging
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+// drawing returns a gradient image with a dark box at x, y, a stand-in for a comic panel.
+func drawing(width, height, x, y int) image.Image {
+	img := image.NewGray(image.Rect(0, 0, width, height))
+
+	for py := 0; py < height; py++ {
+		for px := 0; px < width; px++ {
+			c := color.Gray{Y: uint8(255 * (px + py) / (width + height))}
+
+			if px >= x && px < x+width/4 && py >= y && py < y+height/4 {
+				c.Y = 20
+			}
+
+			img.SetGray(px, py, c)
+		}
+	}
+
+	return img
+}
+
+func TestHashesOfScaledImage(t *testing.T) {
+	original := ComputeHashes(drawing(320, 240, 200, 40))
+	scaled := ComputeHashes(Thumbnail(drawing(320, 240, 200, 40), 100))
+	other := ComputeHashes(drawing(320, 240, 20, 160))
+
+	for _, kind := range []string{"a", "d", "p"} {
+		a, _ := original.Get(kind)
+		b, _ := scaled.Get(kind)
+		c, _ := other.Get(kind)
+
+		if d := Distance(a, b); d > 4 {
+			t.Errorf("%s: expected a scaled image to be similar, distance %d", kind, d)
+		}
+
+		if Distance(a, c) <= Distance(a, b) {
+			t.Errorf("%s: expected a different image to be farther than a scaled one", kind)
+		}
+	}
+}
+
+func TestBKTreeSearch(t *testing.T) {
+	hashes := []uint64{0x0, 0x1, 0x3, 0xff, 0xffff, 0x7}
+	tree := &BKTree{}
+
+	for i, h := range hashes {
+		tree.Add(h, i)
+	}
+
+	tree.Add(0x3, 10)
+
+	if tree.Len() != len(hashes)+1 {
+		t.Errorf("expected %d items, got %d", len(hashes)+1, tree.Len())
+	}
+
+	got := tree.Search(0x1, 1)
+	want := []Match{{1, 0}, {0, 1}, {2, 1}, {10, 1}}
+
+	if len(got) != len(want) {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("expected %v, got %v", want, got)
+			break
+		}
+	}
+
+	// compare with a linear scan
+	for _, query := range []uint64{0x0, 0xf0, 0xfff0} {
+		for max := 0; max <= 16; max += 4 {
+			expected := 0
+
+			for _, h := range append(hashes, 0x3) {
+				if Distance(h, query) <= max {
+					expected++
+				}
+			}
+
+			if n := len(tree.Search(query, max)); n != expected {
+				t.Errorf("search(%x, %d): expected %d matches, got %d", query, max, expected, n)
+			}
+		}
+	}
+}
@@ -0,0 +1,98 @@
+package imaging
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/draw"
+	"image/png"
+)
+
+// Decode decodes a PNG, JPEG or GIF image and returns it with the name of its format.
+func Decode(data []byte) (image.Image, string, error) {
+	img, format, err := image.Decode(bytes.NewReader(data))
+
+	if err != nil {
+		return nil, "", fmt.Errorf("decode: %v", err)
+	}
+
+	return img, format, nil
+}
+
+// Thumbnail scales src down to fit in a size x size square, keeping the aspect ratio. Every pixel of
+// the thumbnail is the average of the source pixels it covers, which keeps the thin lines of the comics
+// visible. Images smaller than size are not scaled up.
+func Thumbnail(src image.Image, size int) *image.RGBA {
+	bounds := src.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+
+	if size < 1 {
+		size = 1
+	}
+
+	dstWidth, dstHeight := width, height
+
+	if width > size || height > size {
+		if width >= height {
+			dstWidth, dstHeight = size, max(1, height*size/width)
+		} else {
+			dstWidth, dstHeight = max(1, width*size/height), size
+		}
+	}
+
+	// the source is converted to RGBA once, reading the pixels through image.Image is slow
+	rgba := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.Draw(rgba, rgba.Bounds(), src, bounds.Min, draw.Src)
+
+	dst := image.NewRGBA(image.Rect(0, 0, dstWidth, dstHeight))
+
+	for y := 0; y < dstHeight; y++ {
+		y0, y1 := y*height/dstHeight, max((y+1)*height/dstHeight, y*height/dstHeight+1)
+
+		for x := 0; x < dstWidth; x++ {
+			x0, x1 := x*width/dstWidth, max((x+1)*width/dstWidth, x*width/dstWidth+1)
+
+			var sum [4]int
+
+			for sy := y0; sy < y1; sy++ {
+				offset := rgba.PixOffset(x0, sy)
+
+				for sx := x0; sx < x1; sx++ {
+					for i := range sum {
+						sum[i] += int(rgba.Pix[offset+i])
+					}
+
+					offset += 4
+				}
+			}
+
+			count := (x1 - x0) * (y1 - y0)
+			offset := dst.PixOffset(x, y)
+
+			for i := range sum {
+				dst.Pix[offset+i] = uint8(sum[i] / count)
+			}
+		}
+	}
+
+	return dst
+}
+
+// EncodePNG encodes img as a PNG image.
+func EncodePNG(img image.Image) ([]byte, error) {
+	var buf bytes.Buffer
+
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, fmt.Errorf("encode png: %v", err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+func max(a, b int) int {
+	if a > b {
+		return a
+	}
+
+	return b
+}
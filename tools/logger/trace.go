@@ -40,9 +40,10 @@ func Initialize(useLog bool) {
 }
 
 // Trace function writers a message about the start and exit of a method. Errors are ignored from Write([]byte) method.
+// When logging is not initialized, Trace does nothing, so it can be called on hot paths such as collection lookups.
 func Trace(msg string) func() {
-	if logWriter == nil {
-		return nil
+	if logWriter == nil || logWriter == ioutil.Discard {
+		return func() {}
 	}
 
 	value := fmt.Sprintf("[%s] starting: %s\n", time.Now().Format("15:04:56"), msg)
@@ -60,7 +61,7 @@ func Trace(msg string) func() {
 }
 
 func Info(msg string) {
-	if logWriter == nil {
+	if logWriter == nil || logWriter == ioutil.Discard {
 		return
 	}
 
@@ -0,0 +1,43 @@
+package util
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+// WriteFileAtomic writes data into a new temporary file in the folder of filename and renames it to filename,
+// so an interrupted write does not leave a truncated file and concurrent writes of the same file do not
+// share the temporary file.
+func WriteFileAtomic(filename string, data []byte) error {
+	temp, err := ioutil.TempFile(filepath.Dir(filename), filepath.Base(filename)+".*.tmp")
+
+	if err != nil {
+		return err
+	}
+
+	if _, err := temp.Write(data); err != nil {
+		temp.Close()
+		os.Remove(temp.Name())
+
+		return err
+	}
+
+	if err := temp.Close(); err != nil {
+		os.Remove(temp.Name())
+		return err
+	}
+
+	// TempFile creates the file readable by the owner only
+	if err := os.Chmod(temp.Name(), 0644); err != nil {
+		os.Remove(temp.Name())
+		return err
+	}
+
+	if err := os.Rename(temp.Name(), filename); err != nil {
+		os.Remove(temp.Name())
+		return err
+	}
+
+	return nil
+}
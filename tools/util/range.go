@@ -0,0 +1,63 @@
+package util
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// ParseRange parses the integer condition of a search term and returns its inclusive bounds. The condition
+// is a number, a range a..b where either end may be missing, or a comparison =n, <n, <=n, >n or >=n.
+func ParseRange(value string) (low, high int, err error) {
+	if i := strings.Index(value, ".."); i >= 0 {
+		low, high = math.MinInt32, math.MaxInt32
+
+		if left := value[:i]; left != "" {
+			if low, err = strconv.Atoi(left); err != nil {
+				return 0, 0, fmt.Errorf("invalid range %q", value)
+			}
+		}
+
+		if right := value[i+2:]; right != "" {
+			if high, err = strconv.Atoi(right); err != nil {
+				return 0, 0, fmt.Errorf("invalid range %q", value)
+			}
+		}
+
+		return low, high, nil
+	}
+
+	for _, op := range []string{">=", "<=", ">", "<", "="} {
+		if !strings.HasPrefix(value, op) {
+			continue
+		}
+
+		n, err := strconv.Atoi(value[len(op):])
+
+		if err != nil {
+			return 0, 0, fmt.Errorf("invalid number %q", value)
+		}
+
+		switch op {
+		case ">=":
+			return n, math.MaxInt32, nil
+		case "<=":
+			return math.MinInt32, n, nil
+		case ">":
+			return n + 1, math.MaxInt32, nil
+		case "<":
+			return math.MinInt32, n - 1, nil
+		}
+
+		return n, n, nil
+	}
+
+	n, err := strconv.Atoi(value)
+
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid number %q", value)
+	}
+
+	return n, n, nil
+}
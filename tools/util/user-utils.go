@@ -32,3 +32,48 @@ func GetXkcdFolder() string {
 func GetIndexFile() string {
 	return fmt.Sprintf("%s/%s", GetXkcdFolder(), config.IndexFile)
 }
+
+// Returns complete filename of the revision history of the comics
+func GetHistoryFile() string {
+	return fmt.Sprintf("%s/%s", GetXkcdFolder(), config.HistoryFile)
+}
+
+// Returns complete filename of the time of the last sync
+func GetSyncFile() string {
+	return fmt.Sprintf("%s/%s", GetXkcdFolder(), config.SyncFile)
+}
+
+// Returns the default output folder of the static site generator
+func GetSiteFolder() string {
+	return fmt.Sprintf("%s/%s", GetXkcdFolder(), config.SiteFolder)
+}
+
+// Returns complete filename of the Atom feed
+func GetAtomFile() string {
+	return fmt.Sprintf("%s/%s", GetXkcdFolder(), config.AtomFile)
+}
+
+// Returns complete filename of the RSS feed
+func GetRSSFile() string {
+	return fmt.Sprintf("%s/%s", GetXkcdFolder(), config.RSSFile)
+}
+
+// Returns the folder of the thumbnail cache
+func GetThumbsFolder() string {
+	return fmt.Sprintf("%s/%s", GetXkcdFolder(), config.ThumbsFolder)
+}
+
+// Returns the folder of the blob store of the comic assets
+func GetBlobsFolder() string {
+	return fmt.Sprintf("%s/%s", GetXkcdFolder(), config.BlobsFolder)
+}
+
+// Returns complete filename of the annotations of the comics
+func GetAnnotationsFile() string {
+	return fmt.Sprintf("%s/%s", GetXkcdFolder(), config.AnnotationsFile)
+}
+
+// Returns the folder of the playlists
+func GetListsFolder() string {
+	return fmt.Sprintf("%s/%s", GetXkcdFolder(), config.ListsFolder)
+}
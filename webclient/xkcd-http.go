@@ -1,6 +1,7 @@
 package webclient
 
 import (
+	"errors"
 	"fmt"
 	"io/ioutil"
 	"net/http"
@@ -20,14 +21,41 @@ func init() {
 	Client = &http.Client{}
 }
 
+// Validators are the cache validators of a response. They are sent back with a conditional request,
+// so the server can answer that the resource has not changed without sending it again.
+type Validators struct {
+	ETag         string
+	LastModified string
+}
+
+// ErrNotModified is returned by GetConditional when the resource has not changed since the validators were received.
+var ErrNotModified = errors.New("not modified")
+
 // Get makes a call to the web server and returns a byte slice with raw data.
 // The calling function should handle the slice either by decoding/unmarshalling the JSON or
 // doing something else with the byte slice.
 func Get(url string) ([]byte, error) {
+	result, _, err := GetConditional(url, Validators{})
+
+	return result, err
+}
+
+// GetConditional works as Get, but it sends the validators of a previous response with the request
+// and returns the validators of the new response. If the server responds that the resource has not
+// changed, the returned error is ErrNotModified.
+func GetConditional(url string, validators Validators) ([]byte, Validators, error) {
 	req, err := http.NewRequest(http.MethodGet, url, nil)
 
 	if err != nil {
-		return nil, fmt.Errorf("NewRequest: %v", err)
+		return nil, Validators{}, fmt.Errorf("NewRequest: %v", err)
+	}
+
+	if validators.ETag != "" {
+		req.Header.Set("If-None-Match", validators.ETag)
+	}
+
+	if validators.LastModified != "" {
+		req.Header.Set("If-Modified-Since", validators.LastModified)
 	}
 
 	start := time.Now()
@@ -36,20 +64,24 @@ func Get(url string) ([]byte, error) {
 	logger.Info(fmt.Sprintf("client.do(req) time: %d", end.Milliseconds()))
 
 	if err != nil {
-		return nil, fmt.Errorf("Get: %v", err)
+		return nil, Validators{}, fmt.Errorf("Get: %v", err)
 	}
 
 	defer resp.Body.Close()
 
+	if resp.StatusCode == http.StatusNotModified {
+		return nil, validators, ErrNotModified
+	}
+
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("response status: %v", resp.Status)
+		return nil, Validators{}, fmt.Errorf("response status: %v", resp.Status)
 	}
 
 	var result []byte
 
 	if result, err = ioutil.ReadAll(resp.Body); err != nil {
-		return nil, fmt.Errorf("ReadAll: %v", err)
+		return nil, Validators{}, fmt.Errorf("ReadAll: %v", err)
 	}
 
-	return result, nil
+	return result, Validators{resp.Header.Get("ETag"), resp.Header.Get("Last-Modified")}, nil
 }
@@ -57,3 +57,36 @@ func TestGetBadRequest(t *testing.T) {
 		t.Errorf("expected invalid response status")
 	}
 }
+
+func TestGetConditionalNotModified(t *testing.T) {
+	saved := Client
+	defer func() { Client = saved }()
+
+	Client = &mocks.MockClient{}
+	mocks.GetDoFunc = func(req *http.Request) (*http.Response, error) {
+		status := http.StatusOK
+
+		if req.Header.Get("If-None-Match") == `"v1"` {
+			status = http.StatusNotModified
+		}
+
+		header := http.Header{}
+		header.Set("ETag", `"v1"`)
+
+		return &http.Response{
+			StatusCode: status,
+			Header:     header,
+			Body:       ioutil.NopCloser(bytes.NewReader([]byte("data"))),
+		}, nil
+	}
+
+	_, validators, err := GetConditional("test-url", Validators{})
+
+	if err != nil || validators.ETag != `"v1"` {
+		t.Fatalf("expected the ETag of the response, got %q, %v", validators.ETag, err)
+	}
+
+	if _, _, err = GetConditional("test-url", validators); err != ErrNotModified {
+		t.Errorf("expected ErrNotModified, got %v", err)
+	}
+}